@@ -0,0 +1,183 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/op"
+)
+
+// Preparer prepares statements, a *sql.Tx or *sqlx.Tx
+// lib/pq only runs COPY FROM STDIN inside a transaction
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// Source yields the rows to copy one at a time, returning io.EOF when done
+type Source func() (tab.Table, error)
+
+// Rows returns a Source yielding rows
+func Rows(rows ...tab.Table) Source {
+	i := 0
+	return func() (tab.Table, error) {
+		if i >= len(rows) {
+			return nil, io.EOF
+		}
+		i++
+		return rows[i-1], nil
+	}
+}
+
+// Copier streams rows into their table with COPY FROM STDIN,
+// each column is encoded by its driver.Valuer
+type Copier struct {
+	// Columns limits the copied columns, all of the first row's by default
+	Columns []tab.Column
+	// Progress is called with the number of rows sent every ProgressEvery rows, and once when done
+	Progress      func(rows int)
+	ProgressEvery int
+	// Hooks run around the COPY statement after the shared ones of tabua.UseHooks, After gets the rows sent
+	Hooks tab.Hooks
+}
+
+// RowError is the error of a row that failed to copy, Row counts from 0 in the Source
+type RowError struct {
+	Row int
+	Err error
+}
+
+func (e RowError) Error() string {
+	return "copy row " + strconv.Itoa(e.Row) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Copy copies the rows of src into the table of its first row and returns how many were sent
+func (c Copier) Copy(ctx context.Context, tx Preparer, src Source) (n int, err error) {
+	first, err := src()
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, RowError{0, err}
+	}
+	cols := c.Columns
+	if len(cols) < 1 {
+		cols = first.Columns()
+	}
+	if len(cols) < 1 {
+		return 0, tab.QueryGenerationError{Message: "No columns to copy"}
+	}
+
+	hooks := tab.SharedHooks(c.Hooks...)
+	q, err := hooks.Generate(tab.Sq{S: copyIn(first, cols)})
+	if err != nil {
+		return 0, err
+	}
+	if q, err = hooks.Before(ctx, q); err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	defer func() {
+		hooks.After(ctx, tab.QueryEvent{Query: q, Duration: time.Since(start), Rows: int64(n), Err: err})
+	}()
+	stmt, err := tx.PrepareContext(ctx, q.SQL())
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	row := first
+	for {
+		if err := c.send(ctx, stmt, row, cols); err != nil {
+			return n, copyErr(err, n)
+		}
+		n++
+		if c.Progress != nil && c.ProgressEvery > 0 && n%c.ProgressEvery == 0 {
+			c.Progress(n)
+		}
+		row, err = src()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, RowError{n, err}
+		}
+	}
+
+	// an empty Exec flushes the buffered rows, the server reports bad rows here
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return n, copyErr(err, n)
+	}
+	if c.Progress != nil {
+		c.Progress(n)
+	}
+	return n, nil
+}
+
+// CopyRows copies rows into the table of the first one and returns how many were sent
+func (c Copier) CopyRows(ctx context.Context, tx Preparer, rows ...tab.Table) (int, error) {
+	return c.Copy(ctx, tx, Rows(rows...))
+}
+
+// Copy copies the rows of src with a default Copier
+func Copy(ctx context.Context, tx Preparer, src Source) (int, error) {
+	return Copier{}.Copy(ctx, tx, src)
+}
+
+// CopyRows copies rows with a default Copier
+func CopyRows(ctx context.Context, tx Preparer, rows ...tab.Table) (int, error) {
+	return Copier{}.CopyRows(ctx, tx, rows...)
+}
+
+// copyIn renders the COPY statement of cols into t, qualified by a non empty schema
+func copyIn(t tab.Table, cols []tab.Column) string {
+	names := make([]string, 0, len(cols))
+	for _, col := range cols {
+		names = append(names, col.Name())
+	}
+	if s, ok := t.(tab.Schemer); ok && len(s.Schema()) > 0 {
+		return pq.CopyInSchema(s.Schema(), t.Name(), names...)
+	}
+	return pq.CopyIn(t.Name(), names...)
+}
+
+func (c Copier) send(ctx context.Context, stmt *sql.Stmt, row tab.Table, cols []tab.Column) error {
+	values := op.InOrder(row.Columns(), cols)
+	if len(values) != len(cols) {
+		return RowError{-1, tab.QueryGenerationError{Message: "row of " + row.Name() + " is missing copied columns"}}
+	}
+	args := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	_, err := stmt.ExecContext(ctx, args...)
+	return err
+}
+
+var copyLine = regexp.MustCompile(`line (\d+)`)
+
+// copyErr attributes err to a row, the server names the failing line of
+// the COPY input, otherwise it's the row being sent
+func copyErr(err error, sent int) error {
+	if re, ok := err.(RowError); ok && re.Row < 0 {
+		return RowError{sent, re.Err}
+	}
+	if pqErr, ok := err.(*pq.Error); ok {
+		if m := copyLine.FindStringSubmatch(pqErr.Where); len(m) > 1 {
+			if line, convErr := strconv.Atoi(m[1]); convErr == nil {
+				return RowError{line - 1, err}
+			}
+		}
+	}
+	return RowError{sent, err}
+}
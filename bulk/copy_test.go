@@ -0,0 +1,207 @@
+package bulk
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// users is a table of the app schema
+type users struct {
+	ID   userID
+	Nick userNick
+}
+
+func (users) Name() string                  { return "users" }
+func (users) Schema() string                { return "app" }
+func (users) Constraints() []tab.Constraint { return nil }
+func (u users) Columns() []tab.Column       { return []tab.Column{u.ID, u.Nick} }
+
+type userID int64
+
+func (userID) Name() string                   { return "id" }
+func (userID) Table() tab.Table               { return users{} }
+func (userID) SQLType() string                { return "int8" }
+func (userID) NonNull() bool                  { return true }
+func (c userID) Value() (driver.Value, error) { return int64(c), nil }
+
+type userNick string
+
+func (userNick) Name() string                   { return "nick" }
+func (userNick) Table() tab.Table               { return users{} }
+func (userNick) SQLType() string                { return "text" }
+func (userNick) NonNull() bool                  { return true }
+func (c userNick) Value() (driver.Value, error) { return string(c), nil }
+
+// events is a table without a schema
+type events struct{ ID eventID }
+
+func (events) Name() string                  { return "events" }
+func (events) Constraints() []tab.Constraint { return nil }
+func (e events) Columns() []tab.Column       { return []tab.Column{e.ID} }
+
+type eventID int64
+
+func (eventID) Name() string                   { return "id" }
+func (eventID) Table() tab.Table               { return events{} }
+func (eventID) SQLType() string                { return "int8" }
+func (eventID) NonNull() bool                  { return true }
+func (c eventID) Value() (driver.Value, error) { return int64(c), nil }
+
+// copyDB is a driver recording the COPY statement and the rows sent to it
+type copyDB struct {
+	query string
+	rows  [][]driver.Value
+	// fail returns the error of sending args, an empty args flushes
+	fail func(args []driver.Value) error
+}
+
+func (d *copyDB) Connect(context.Context) (driver.Conn, error) { return d, nil }
+func (d *copyDB) Driver() driver.Driver                        { return nil }
+func (d *copyDB) Prepare(query string) (driver.Stmt, error) {
+	d.query = query
+	return copyStmt{d}, nil
+}
+func (d *copyDB) Close() error              { return nil }
+func (d *copyDB) Begin() (driver.Tx, error) { return d, nil }
+func (d *copyDB) Commit() error             { return nil }
+func (d *copyDB) Rollback() error           { return nil }
+
+type copyStmt struct{ d *copyDB }
+
+func (s copyStmt) Close() error  { return nil }
+func (s copyStmt) NumInput() int { return -1 }
+func (s copyStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.d.fail != nil {
+		if err := s.d.fail(args); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) > 0 {
+		s.d.rows = append(s.d.rows, args)
+	}
+	return driver.RowsAffected(0), nil
+}
+func (s copyStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("no queries")
+}
+
+func begin(t *testing.T, d *copyDB) *sql.Tx {
+	t.Helper()
+	tx, err := sql.OpenDB(d).Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+func TestCopy(t *testing.T) {
+	d := &copyDB{}
+	progress := []int{}
+	c := Copier{Progress: func(n int) { progress = append(progress, n) }, ProgressEvery: 2}
+	n, err := c.CopyRows(context.Background(), begin(t, d), users{1, "a"}, users{2, "b"}, users{3, "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `COPY "app"."users" ("id", "nick") FROM STDIN`; d.query != want {
+		t.Errorf("got %q, want %q", d.query, want)
+	}
+	if want := [][]driver.Value{{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}}; n != 3 || !reflect.DeepEqual(d.rows, want) {
+		t.Errorf("got %d rows %v, want %v", n, d.rows, want)
+	}
+	if want := []int{2, 3}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("got progress %v, want %v", progress, want)
+	}
+}
+
+func TestCopyStatement(t *testing.T) {
+	cases := []struct {
+		name string
+		c    Copier
+		row  tab.Table
+		want string
+	}{
+		{"schema", Copier{}, users{}, `COPY "app"."users" ("id", "nick") FROM STDIN`},
+		{"columns", Copier{Columns: []tab.Column{userNick("")}}, users{}, `COPY "app"."users" ("nick") FROM STDIN`},
+		{"no schema", Copier{}, events{}, `COPY "events" ("id") FROM STDIN`},
+	}
+	for _, c := range cases {
+		d := &copyDB{}
+		if _, err := c.c.CopyRows(context.Background(), begin(t, d), c.row); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if d.query != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, d.query, c.want)
+		}
+	}
+}
+
+func TestCopyEmpty(t *testing.T) {
+	d := &copyDB{}
+	if n, err := Copy(context.Background(), begin(t, d), Rows()); n != 0 || err != nil || d.query != "" {
+		t.Errorf("got %d %v and statement %q, want nothing copied", n, err, d.query)
+	}
+}
+
+func TestCopyErrors(t *testing.T) {
+	flush := &pq.Error{Code: "22P02", Message: "invalid input syntax", Where: "COPY users, line 2, column id"}
+	d := &copyDB{fail: func(args []driver.Value) error {
+		if len(args) == 0 {
+			return flush
+		}
+		return nil
+	}}
+	n, err := CopyRows(context.Background(), begin(t, d), users{1, "a"}, users{2, "b"}, users{3, "c"})
+	if re, ok := err.(RowError); !ok || re.Row != 1 || re.Err != flush || n != 3 {
+		t.Errorf("got %d %v, want row 1 failing the flush", n, err)
+	}
+
+	srcErr := errors.New("source failed")
+	i := 0
+	src := func() (tab.Table, error) {
+		if i++; i > 2 {
+			return nil, srcErr
+		}
+		return users{userID(i), "a"}, nil
+	}
+	n, err = Copy(context.Background(), begin(t, &copyDB{}), src)
+	if re, ok := err.(RowError); !ok || re.Row != 2 || re.Err != srcErr || n != 2 {
+		t.Errorf("got %d %v, want row 2 failing in the source", n, err)
+	}
+
+	c := Copier{Columns: []tab.Column{userID(0), userNick("")}}
+	_, err = c.CopyRows(context.Background(), begin(t, &copyDB{}), events{})
+	if re, ok := err.(RowError); !ok || re.Row != 0 {
+		t.Errorf("got %v, want row 0 missing copied columns", err)
+	}
+}
+
+func TestCopyErr(t *testing.T) {
+	plain := errors.New("connection reset")
+	cases := []struct {
+		name string
+		err  error
+		sent int
+		row  int
+	}{
+		{"line", &pq.Error{Where: "COPY users, line 5, column nick: \"x\""}, 9, 4},
+		{"first line", &pq.Error{Where: "COPY users, line 1"}, 9, 0},
+		{"no line", &pq.Error{Where: "COPY users"}, 9, 9},
+		{"not pq", plain, 3, 3},
+		{"unnumbered row", RowError{-1, plain}, 7, 7},
+	}
+	for _, c := range cases {
+		re, ok := copyErr(c.err, c.sent).(RowError)
+		if !ok || re.Row != c.row {
+			t.Errorf("%s: got %v, want row %d", c.name, re, c.row)
+		}
+	}
+}
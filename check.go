@@ -0,0 +1,778 @@
+package tabua
+
+import (
+	"cmp"
+	"database/sql/driver"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errUncheckable is returned for CHECK expressions, or values, the evaluator doesn't support
+var errUncheckable = errors.New("check can't be evaluated")
+
+// row holds the driver values of columns by name
+type row map[string]driver.Value
+
+// expr is a parsed CHECK condition, it evaluates to nil (NULL) or bool
+type expr func(r row) (interface{}, error)
+
+// evalCheck evaluates the CHECK definition def, as returned by pg_get_constraintdef, on the values of r
+// A CHECK only fails when false, ok is false when def can't be evaluated on r
+// Supported are comparisons of a column with a literal, IS [NOT] NULL, column = ANY (ARRAY[...]) of
+// literals, AND and OR. Casts are only followed when they leave the value as it is, (ratio)::integer
+// rounds and can't be evaluated, nor can functions, arithmetic, NOT or ALL
+// Strings are only compared for equality, their order depends on the database collation
+func evalCheck(def string, r row) (pass bool, ok bool) {
+	def = strings.TrimSpace(def)
+	def = strings.TrimSuffix(def, " NOT VALID")
+	def = strings.TrimSuffix(def, " NO INHERIT")
+	if !strings.HasPrefix(def, "CHECK") {
+		return false, false
+	}
+	toks, err := tokenize(def[len("CHECK"):])
+	if err != nil {
+		return false, false
+	}
+	p := &parser{toks: toks}
+	n, err := p.or()
+	if err != nil || p.pos < len(p.toks) || n.kind != nCond {
+		return false, false
+	}
+	v, err := n.cond(r)
+	if err != nil {
+		return false, false
+	}
+	switch v := v.(type) {
+	case nil:
+		return true, true
+	case bool:
+		return v, true
+	}
+	return false, false
+}
+
+type tokenKind int
+
+const (
+	tEOF tokenKind = iota
+	tIdent
+	tQuoted
+	tNumber
+	tString
+	tSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// tokenize splits a CHECK expression, unquoted identifiers are lower cased
+func tokenize(s string) ([]token, error) {
+	toks := []token{}
+	for len(s) > 0 {
+		c, size := utf8.DecodeRuneInString(s)
+		switch {
+		case unicode.IsSpace(c):
+			s = s[size:]
+		case c == '"' || c == '\'':
+			// quotes are escaped by doubling them
+			buf := &strings.Builder{}
+			i := 1
+			for {
+				end := strings.IndexRune(s[i:], c)
+				if end < 0 {
+					return nil, errUncheckable
+				}
+				buf.WriteString(s[i : i+end])
+				i += end + 1
+				if i < len(s) && rune(s[i]) == c {
+					buf.WriteRune(c)
+					i++
+					continue
+				}
+				break
+			}
+			kind := tString
+			if c == '"' {
+				kind = tQuoted
+			}
+			toks = append(toks, token{kind, buf.String()})
+			s = s[i:]
+		case unicode.IsDigit(c) || c == '.' && len(s) > 1 && unicode.IsDigit(rune(s[1])):
+			i := 0
+			for i < len(s) && (unicode.IsDigit(rune(s[i])) || s[i] == '.') {
+				i++
+			}
+			if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+				i++
+				if i < len(s) && (s[i] == '+' || s[i] == '-') {
+					i++
+				}
+				for i < len(s) && unicode.IsDigit(rune(s[i])) {
+					i++
+				}
+			}
+			toks = append(toks, token{tNumber, s[:i]})
+			s = s[i:]
+		case unicode.IsLetter(c) || c == '_':
+			i := 0
+			for i < len(s) {
+				r, n := utf8.DecodeRuneInString(s[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' {
+					break
+				}
+				i += n
+			}
+			toks = append(toks, token{tIdent, strings.ToLower(s[:i])})
+			s = s[i:]
+		default:
+			sym := ""
+			for _, op := range []string{"::", "<>", "!=", "<=", ">=", "=", "<", ">", "(", ")", "[", "]", ",", "-"} {
+				if strings.HasPrefix(s, op) {
+					sym = op
+					break
+				}
+			}
+			if len(sym) < 1 {
+				return nil, errUncheckable
+			}
+			toks = append(toks, token{tSymbol, sym})
+			s = s[len(sym):]
+		}
+	}
+	return toks, nil
+}
+
+// parser parses CHECK expressions by recursive descent, with the precedence of postgres
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return token{}
+}
+
+// keyword consumes the unquoted identifier k
+func (p *parser) keyword(k string) bool {
+	if t := p.peek(); t.kind == tIdent && t.text == k {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// symbol consumes the symbol s
+func (p *parser) symbol(s string) bool {
+	if t := p.peek(); t.kind == tSymbol && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(s string) error {
+	if !p.symbol(s) {
+		return errUncheckable
+	}
+	return nil
+}
+
+type nodeKind int
+
+const (
+	nCond nodeKind = iota
+	nColumn
+	nLiteral
+)
+
+// node is a parsed part of a CHECK expression: a condition, a column or a literal
+type node struct {
+	kind nodeKind
+	cond expr
+	// col is the name of a column, cast to casts when evaluated
+	col   string
+	casts []sqlType
+	// v is the value of a literal, casts applied, []interface{} for arrays
+	v interface{}
+}
+
+// value returns the value of a column or literal node in r
+func (n node) value(r row) (interface{}, error) {
+	if n.kind == nLiteral {
+		return n.v, nil
+	}
+	v, err := column(r, n.col)
+	for _, t := range n.casts {
+		if err != nil {
+			break
+		}
+		v, err = castColumn(v, t)
+	}
+	return v, err
+}
+
+func condition(e expr) node {
+	return node{kind: nCond, cond: e}
+}
+
+func (p *parser) or() (node, error) {
+	l, err := p.and()
+	if err != nil {
+		return node{}, err
+	}
+	for p.keyword("or") {
+		r, err := p.and()
+		if err != nil {
+			return node{}, err
+		}
+		if l.kind != nCond || r.kind != nCond {
+			return node{}, errUncheckable
+		}
+		l = condition(logic(l.cond, r.cond, true))
+	}
+	return l, nil
+}
+
+func (p *parser) and() (node, error) {
+	l, err := p.compare()
+	if err != nil {
+		return node{}, err
+	}
+	for p.keyword("and") {
+		r, err := p.compare()
+		if err != nil {
+			return node{}, err
+		}
+		if l.kind != nCond || r.kind != nCond {
+			return node{}, errUncheckable
+		}
+		l = condition(logic(l.cond, r.cond, false))
+	}
+	return l, nil
+}
+
+// compare parses column IS [NOT] NULL, column = ANY (array) and comparisons of a column with a literal
+func (p *parser) compare() (node, error) {
+	l, err := p.operand()
+	if err != nil {
+		return node{}, err
+	}
+	if p.keyword("is") {
+		not := p.keyword("not")
+		if !p.keyword("null") || l.kind != nColumn {
+			return node{}, errUncheckable
+		}
+		return condition(func(r row) (interface{}, error) {
+			v, err := l.value(r)
+			if err != nil {
+				return nil, err
+			}
+			return (v == nil) != not, nil
+		}), nil
+	}
+	op := p.peek()
+	if op.kind != tSymbol {
+		return l, nil
+	}
+	switch op.text {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+		p.pos++
+	default:
+		return l, nil
+	}
+	if p.keyword("any") {
+		arr, err := p.operand()
+		if err != nil {
+			return node{}, err
+		}
+		items, ok := arr.v.([]interface{})
+		if op.text != "=" || l.kind != nColumn || arr.kind != nLiteral || !ok {
+			return node{}, errUncheckable
+		}
+		return condition(in(l, items)), nil
+	}
+	rt, err := p.operand()
+	if err != nil {
+		return node{}, err
+	}
+	if l.kind == rt.kind || l.kind == nCond || rt.kind == nCond {
+		return node{}, errUncheckable
+	}
+	for _, n := range []node{l, rt} {
+		if _, ok := n.v.([]interface{}); ok {
+			return node{}, errUncheckable
+		}
+	}
+	return condition(func(r row) (interface{}, error) {
+		a, err := l.value(r)
+		if err != nil {
+			return nil, err
+		}
+		b, err := rt.value(r)
+		if err != nil {
+			return nil, err
+		}
+		return compare(op.text, a, b)
+	}), nil
+}
+
+// in returns the condition of l = ANY (items), NULL when no item matches and one is NULL
+func in(l node, items []interface{}) expr {
+	return func(r row) (interface{}, error) {
+		a, err := l.value(r)
+		if err != nil {
+			return nil, err
+		}
+		null := false
+		for _, item := range items {
+			c, err := compare("=", a, item)
+			if err != nil {
+				return nil, err
+			}
+			if c == nil {
+				null = true
+			} else if c.(bool) {
+				return true, nil
+			}
+		}
+		if null {
+			return nil, nil
+		}
+		return false, nil
+	}
+}
+
+// operand parses a value followed by casts, literals are cast right away and columns once evaluated
+func (p *parser) operand() (node, error) {
+	n, err := p.primary()
+	if err != nil {
+		return node{}, err
+	}
+	for p.symbol("::") {
+		t, err := p.sqlType()
+		if err != nil {
+			return node{}, err
+		}
+		switch n.kind {
+		case nColumn:
+			n.casts = append(n.casts, t)
+		case nLiteral:
+			if n.v, err = castLiteral(n.v, t); err != nil {
+				return node{}, err
+			}
+		default:
+			return node{}, errUncheckable
+		}
+	}
+	return n, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.peek()
+	p.pos++
+	switch t.kind {
+	case tNumber:
+		return numberLiteral(t.text, false)
+	case tString:
+		return node{kind: nLiteral, v: t.text}, nil
+	case tQuoted:
+		return node{kind: nColumn, col: t.text}, nil
+	case tSymbol:
+		switch t.text {
+		case "-":
+			// negative constants, other expressions are arithmetic
+			if n := p.peek(); n.kind == tNumber {
+				p.pos++
+				return numberLiteral(n.text, true)
+			}
+		case "(":
+			n, err := p.or()
+			if err != nil {
+				return node{}, err
+			}
+			return n, p.expect(")")
+		}
+	case tIdent:
+		switch t.text {
+		case "true", "false":
+			return node{kind: nLiteral, v: t.text == "true"}, nil
+		case "null":
+			return node{kind: nLiteral}, nil
+		case "array":
+			return p.array()
+		case "and", "or", "not", "is", "any", "all":
+			return node{}, errUncheckable
+		}
+		// functions aren't evaluated
+		if p.peek().text == "(" {
+			return node{}, errUncheckable
+		}
+		return node{kind: nColumn, col: t.text}, nil
+	}
+	return node{}, errUncheckable
+}
+
+// numberLiteral returns the literal of the number s, negated when neg
+func numberLiteral(s string, neg bool) (node, error) {
+	n, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return node{}, errUncheckable
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return node{kind: nLiteral, v: n}, nil
+}
+
+// array parses the literals of ARRAY[...]
+func (p *parser) array() (node, error) {
+	if err := p.expect("["); err != nil {
+		return node{}, err
+	}
+	items := []interface{}{}
+	for !p.symbol("]") {
+		if len(items) > 0 {
+			if err := p.expect(","); err != nil {
+				return node{}, err
+			}
+		}
+		n, err := p.operand()
+		if err != nil {
+			return node{}, err
+		}
+		if _, ok := n.v.([]interface{}); ok || n.kind != nLiteral {
+			return node{}, errUncheckable
+		}
+		items = append(items, n.v)
+	}
+	return node{kind: nLiteral, v: items}, nil
+}
+
+// sqlType is the type of a cast
+type sqlType struct {
+	name string
+	// mod is set for types with a modifier, numeric(4,2) or character varying(5)
+	mod   bool
+	array int
+}
+
+// sqlType parses the type of a cast, multi word types are joined by a space
+func (p *parser) sqlType() (sqlType, error) {
+	t := sqlType{}
+	if tok := p.peek(); tok.kind != tIdent && tok.kind != tQuoted {
+		return t, errUncheckable
+	}
+	t.name = p.peek().text
+	p.pos++
+	// character varying, timestamp with time zone, double precision
+	for {
+		switch w := p.peek(); w.text {
+		case "varying", "precision", "with", "without", "time", "zone":
+			if w.kind == tIdent {
+				t.name += " " + w.text
+				p.pos++
+				continue
+			}
+		}
+		break
+	}
+	if p.symbol("(") {
+		t.mod = true
+		for !p.symbol(")") {
+			if tok := p.peek(); tok.kind != tNumber && tok.text != "," {
+				return t, errUncheckable
+			}
+			p.pos++
+		}
+	}
+	for p.symbol("[") {
+		if err := p.expect("]"); err != nil {
+			return t, err
+		}
+		t.array++
+	}
+	return t, nil
+}
+
+// castLiteral casts the literal v to t, when the value stays the same
+func castLiteral(v interface{}, t sqlType) (interface{}, error) {
+	if items, ok := v.([]interface{}); ok {
+		if t.array < 1 {
+			return nil, errUncheckable
+		}
+		elem := t
+		elem.array--
+		cast := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			c, err := castLiteral(item, elem)
+			if err != nil {
+				return nil, err
+			}
+			cast = append(cast, c)
+		}
+		return cast, nil
+	}
+	if v == nil {
+		return nil, nil
+	}
+	if t.array > 0 || t.mod {
+		return nil, errUncheckable
+	}
+	switch t.name {
+	case "text", "character varying", "varchar":
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case "numeric", "decimal":
+		if n, ok := literalRat(v); ok {
+			return n, nil
+		}
+	case "double precision", "float8":
+		switch v := v.(type) {
+		case float64:
+			return v, nil
+		case *big.Rat:
+			f, _ := v.Float64()
+			return f, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil && !math.IsNaN(f) {
+				return f, nil
+			}
+		}
+	case "boolean", "bool":
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	default:
+		// integer literals written as strings, '-1'::integer
+		if n, ok := literalRat(v); ok && fitsInt(n, t.name) {
+			return n, nil
+		}
+	}
+	return nil, errUncheckable
+}
+
+// literalRat returns the number of a numeric or string literal
+func literalRat(v interface{}) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case *big.Rat:
+		return v, true
+	case string:
+		return new(big.Rat).SetString(strings.TrimSpace(v))
+	}
+	return nil, false
+}
+
+// castColumn casts the value v of a column to t, only to a type of the same values
+// Numbers, text of numbers, dates and modifiers may change a value or its comparisons
+func castColumn(v interface{}, t sqlType) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if t.mod || t.array > 0 {
+		return nil, errUncheckable
+	}
+	switch v := v.(type) {
+	case string:
+		switch t.name {
+		case "text", "character varying", "varchar":
+			return v, nil
+		}
+	case *big.Rat:
+		if t.name == "numeric" || t.name == "decimal" || fitsInt(v, t.name) {
+			return v, nil
+		}
+	case float64:
+		if t.name == "double precision" || t.name == "float8" {
+			return v, nil
+		}
+	case bool:
+		if t.name == "boolean" || t.name == "bool" {
+			return v, nil
+		}
+	}
+	return nil, errUncheckable
+}
+
+// fitsInt reports if n is an integer in the range of the integer type name
+func fitsInt(n *big.Rat, name string) bool {
+	var lo, hi int64
+	switch name {
+	case "smallint", "int2":
+		lo, hi = math.MinInt16, math.MaxInt16
+	case "integer", "int", "int4":
+		lo, hi = math.MinInt32, math.MaxInt32
+	case "bigint", "int8":
+		lo, hi = math.MinInt64, math.MaxInt64
+	default:
+		return false
+	}
+	if !n.IsInt() || !n.Num().IsInt64() {
+		return false
+	}
+	i := n.Num().Int64()
+	return i >= lo && i <= hi
+}
+
+// column returns the value of the column called name, which must be in the row
+func column(r row, name string) (interface{}, error) {
+	v, ok := r[name]
+	if !ok {
+		return nil, errUncheckable
+	}
+	switch v := v.(type) {
+	case nil, bool, string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int64:
+		return new(big.Rat).SetInt64(v), nil
+	case float64:
+		// compared as double precision, as postgres does, not by its exact binary value
+		if !math.IsNaN(v) {
+			return v, nil
+		}
+	}
+	return nil, errUncheckable
+}
+
+// logic combines l and r with OR or AND, in three-valued logic
+func logic(l, r expr, or bool) expr {
+	return func(rw row) (interface{}, error) {
+		null := false
+		for _, e := range []expr{l, r} {
+			v, err := e(rw)
+			if err != nil {
+				return nil, err
+			}
+			switch v := v.(type) {
+			case nil:
+				null = true
+			case bool:
+				// true decides an OR, false an AND
+				if v == or {
+					return or, nil
+				}
+			default:
+				return nil, errUncheckable
+			}
+		}
+		if null {
+			return nil, nil
+		}
+		return !or, nil
+	}
+}
+
+// compare returns a op b, nil when either is NULL
+func compare(op string, a, b interface{}) (interface{}, error) {
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	// numeric columns are read as strings
+	if s, ok := a.(string); ok {
+		if isNumber(b) {
+			if n, ok := new(big.Rat).SetString(s); ok {
+				a = n
+			}
+		}
+	}
+	if s, ok := b.(string); ok {
+		if isNumber(a) {
+			if n, ok := new(big.Rat).SetString(s); ok {
+				b = n
+			}
+		}
+	}
+	c, err := order(op, a, b)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "=":
+		return c == 0, nil
+	case "<>", "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+// order compares a and b, strings and bools only for equality
+func order(op string, a, b interface{}) (int, error) {
+	if f, g, ok := floats(a, b); ok {
+		return cmp.Compare(f, g), nil
+	}
+	switch x := a.(type) {
+	case *big.Rat:
+		y, ok := b.(*big.Rat)
+		if !ok {
+			return 0, errUncheckable
+		}
+		return x.Cmp(y), nil
+	case string:
+		y, ok := b.(string)
+		if !ok || op != "=" && op != "<>" && op != "!=" {
+			return 0, errUncheckable
+		}
+		if x != y {
+			return 1, nil
+		}
+		return 0, nil
+	case bool:
+		y, ok := b.(bool)
+		if !ok || op != "=" && op != "<>" && op != "!=" {
+			return 0, errUncheckable
+		}
+		if x != y {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, errUncheckable
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case *big.Rat, float64:
+		return true
+	}
+	return false
+}
+
+// floats returns a and b as float64 when either is one and both are numbers
+func floats(a, b interface{}) (float64, float64, bool) {
+	_, af := a.(float64)
+	_, bf := b.(float64)
+	if !af && !bf {
+		return 0, 0, false
+	}
+	toFloat := func(v interface{}) (float64, bool) {
+		switch v := v.(type) {
+		case float64:
+			return v, true
+		case *big.Rat:
+			f, _ := v.Float64()
+			return f, true
+		}
+		return 0, false
+	}
+	f, ok := toFloat(a)
+	g, ok2 := toFloat(b)
+	return f, g, ok && ok2
+}
@@ -0,0 +1,86 @@
+package tabua
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEvalCheck(t *testing.T) {
+	cases := []struct {
+		name string
+		def  string
+		r    row
+		pass bool
+		ok   bool
+	}{
+		{"integer", `CHECK ((quantity > 0))`, row{"quantity": int64(1)}, true, true},
+		{"integer fails", `CHECK ((quantity > 0))`, row{"quantity": int64(0)}, false, true},
+		{"null passes", `CHECK ((quantity > 0))`, row{"quantity": nil}, true, true},
+		{"not valid", `CHECK ((quantity > 0)) NOT VALID`, row{"quantity": int64(-1)}, false, true},
+		{"no inherit", `CHECK ((quantity > 0)) NO INHERIT`, row{"quantity": int64(1)}, true, true},
+		{"quoted column", `CHECK (("Order" >= 0))`, row{"Order": int64(0)}, true, true},
+
+		{"float cast", `CHECK ((ratio <= (0.1)::double precision))`, row{"ratio": 0.1}, true, true},
+		{"float equal", `CHECK ((ratio = (0.1)::double precision))`, row{"ratio": 0.1}, true, true},
+		{"float next", `CHECK ((ratio = (0.3)::double precision))`, row{"ratio": math.Nextafter(0.3, 1)}, false, true},
+		{"float above", `CHECK ((ratio <= (0.1)::double precision))`, row{"ratio": 0.2}, false, true},
+		{"float nan", `CHECK ((ratio <= (0.1)::double precision))`, row{"ratio": math.NaN()}, false, false},
+		{"numeric", `CHECK ((price > (0)::numeric))`, row{"price": "1.50"}, true, true},
+		{"numeric string", `CHECK ((price > (0)::numeric))`, row{"price": []byte("0")}, false, true},
+		{"string literal cast", `CHECK ((quantity <= '5'::integer))`, row{"quantity": int64(5)}, true, true},
+		{"reversed", `CHECK ((0 < quantity))`, row{"quantity": int64(0)}, false, true},
+		{"same type cast", `CHECK (((quantity)::bigint > 0))`, row{"quantity": int64(1)}, true, true},
+		{"decimal", `CHECK ((discount <= 0.30))`, row{"discount": "0.3"}, true, true},
+
+		{"any", `CHECK ((status = ANY (ARRAY['active'::text, 'closed'::text])))`, row{"status": "closed"}, true, true},
+		{"any fails", `CHECK ((status = ANY (ARRAY['active'::text, 'closed'::text])))`, row{"status": "gone"}, false, true},
+		{"any varchar", `CHECK (((status)::text = ANY ((ARRAY['active'::character varying, 'closed'::character varying])::text[])))`, row{"status": "active"}, true, true},
+		{"any with null", `CHECK ((status = ANY (ARRAY['active'::text, NULL::text])))`, row{"status": "gone"}, true, true},
+		{"any numbers", `CHECK ((level = ANY (ARRAY[1, 2, 3])))`, row{"level": int64(2)}, true, true},
+		{"quote in a literal", `CHECK ((name <> 'it''s'::text))`, row{"name": "it's"}, false, true},
+
+		{"and", `CHECK (((min_qty >= 0) AND (max_qty <= 10)))`, row{"min_qty": int64(1), "max_qty": int64(11)}, false, true},
+		{"or", `CHECK (((start_at IS NULL) OR (end_at IS NOT NULL)))`, row{"start_at": "2024-01-01", "end_at": nil}, false, true},
+		{"or null", `CHECK (((kind = 'a'::text) OR (size > 1)))`, row{"kind": "b", "size": nil}, true, true},
+		{"bool", `CHECK ((active = true))`, row{"active": false}, false, true},
+		{"negative literal", `CHECK ((balance >= '-100'::integer))`, row{"balance": int64(-100)}, true, true},
+
+		{"integer cast", `CHECK (((ratio)::integer >= 1))`, row{"ratio": 0.6}, false, false},
+		{"integer cast of a literal", `CHECK ((ratio >= (0.6)::integer))`, row{"ratio": 0.6}, false, false},
+		{"numeric modifier", `CHECK (((price)::numeric(4,1) > 1.25))`, row{"price": "1.25"}, false, false},
+		{"numeric cast of text", `CHECK (((code)::numeric > 1))`, row{"code": "01.5"}, false, false},
+		{"text of a number", `CHECK (((quantity)::text = '1'::text))`, row{"quantity": int64(1)}, false, false},
+		{"date", `CHECK (((created_at)::date >= '2024-01-01'::date))`, row{"created_at": "2024-01-01 10:00"}, false, false},
+		{"varchar modifier", `CHECK (((code)::character varying(2) = 'ab'::text))`, row{"code": "abc"}, false, false},
+		{"literal out of range", `CHECK ((level < '40000'::smallint))`, row{"level": int64(1)}, false, false},
+		{"column out of range", `CHECK (((level)::integer > 0))`, row{"level": int64(1) << 40}, false, false},
+		{"columns", `CHECK ((max_qty >= min_qty))`, row{"min_qty": int64(1), "max_qty": int64(0)}, false, false},
+		{"literals", `CHECK ((1 = 1))`, row{}, false, false},
+		{"all", `CHECK ((code <> ALL (ARRAY['x'::text, 'y'::text])))`, row{"code": "y"}, false, false},
+		{"not", `CHECK ((NOT (archived AND (deleted_at IS NULL))))`, row{"archived": true, "deleted_at": nil}, false, false},
+		{"arithmetic", `CHECK (((a + (b * 2)) - 1 > 4))`, row{"a": int64(1), "b": int64(2)}, false, false},
+		{"negation", `CHECK ((- ratio < (0)::double precision))`, row{"ratio": 0.1}, false, false},
+		{"function", `CHECK ((char_length(name) <= 3))`, row{"name": "ação"}, false, false},
+		{"function of a cast", `CHECK ((length((code)::text) = 2))`, row{"code": "br"}, false, false},
+		{"boolean column", `CHECK (active)`, row{"active": false}, false, false},
+
+		{"like", `CHECK ((email ~~ '%@%'::text))`, row{"email": "a@b.c"}, false, false},
+		{"string order", `CHECK ((name < 'm'::text))`, row{"name": "a"}, false, false},
+		{"division", `CHECK (((total / count) > 1))`, row{"total": int64(4), "count": int64(2)}, false, false},
+		{"other function", `CHECK ((length(TRIM(BOTH FROM name)) > 0))`, row{"name": "a"}, false, false},
+		{"now", `CHECK ((created_at <= now()))`, row{"created_at": "2024-01-01"}, false, false},
+		{"missing column", `CHECK ((quantity > 0))`, row{}, false, false},
+		{"mixed types", `CHECK ((quantity = 'a'::text))`, row{"quantity": int64(1)}, false, false},
+		{"not a boolean", `CHECK ((quantity + 1))`, row{"quantity": int64(1)}, false, false},
+		{"not a check", `UNIQUE (email)`, row{"email": "a"}, false, false},
+		{"unbalanced", `CHECK ((quantity > 0)`, row{"quantity": int64(1)}, false, false},
+		{"unterminated string", `CHECK ((name = 'a))`, row{"name": "a"}, false, false},
+		{"unsupported value", `CHECK ((quantity > 0))`, row{"quantity": []int{1}}, false, false},
+	}
+	for _, c := range cases {
+		pass, ok := evalCheck(c.def, c.r)
+		if pass != c.pass || ok != c.ok {
+			t.Errorf("%s: got %v %v, want %v %v", c.name, pass, ok, c.pass, c.ok)
+		}
+	}
+}
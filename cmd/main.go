@@ -19,7 +19,11 @@ var (
 	pathFlag     = flag.String("p", "./", "path to save models")
 	packageFlag  = flag.String("pkg", "generated/models", "package path")
 	filterFlag   = flag.String("f", "", "filter tables to reverse")
-	schemaFlag   = flag.String("sch", "", "database schema, default is 'public'")
+	schemaFlag   = flag.String("sch", "", "comma separated database schemas, default is 'public'")
+	typesFlag    = flag.String("types", "", "JSON file mapping SQL types and columns to Go types")
+	deletedFlag  = flag.String("softdelete", "", "column marking rows deleted, tables having it nullable soft delete")
+	versionFlag  = flag.String("versioncol", "", "version column, tables having it lock updates optimistically")
+	registerFlag = flag.Bool("register", false, "register tables with tabua.Register when their package is imported")
 )
 
 func main() {
@@ -34,7 +38,8 @@ func main() {
 		filter.Tables = strings.Split(*filterFlag, ",")
 	}
 	if len(*schemaFlag) > 0 {
-		filter.Schema = *schemaFlag
+		filter.Schema = ""
+		filter.Schemas = strings.Split(*schemaFlag, ",")
 	}
 
 	db, err := sqlx.Connect("postgres", *dbStringFlag)
@@ -45,15 +50,31 @@ func main() {
 
 	r, _ := reverse.New(db, reverse.SQLFromPsql)
 	tables, err := r.Run(filter)
-	if err != nil {
+	if missing, ok := err.(reverse.MissingTablesErr); ok {
+		log.Println("warning:", missing)
+	} else if err != nil {
 		panic(err)
 	}
-	gen := generate.Generator{PackagePath: *packageFlag}
+	gen := generate.Generator{
+		PackagePath: *packageFlag,
+		SchemaDirs:  len(filter.SchemaList()) > 1,
+
+		SoftDeleteColumn: *deletedFlag,
+		VersionColumn:    *versionFlag,
+		Register:         *registerFlag,
+	}
+	if len(*typesFlag) > 0 {
+		conf, err := generate.LoadConfig(*typesFlag)
+		if err != nil {
+			panic(err)
+		}
+		conf.Apply(&gen)
+	}
 
 	buf := &bytes.Buffer{}
 	for _, t := range tables {
 		f, pkg := gen.Run(t)
-		path := *pathFlag + pkg
+		path := *pathFlag + gen.Dir(t)
 		filename := path + "/" + pkg + ".go"
 		err = os.MkdirAll(path, os.ModeDir)
 		if err != nil {
@@ -68,5 +89,8 @@ func main() {
 		}
 		buf.Reset()
 	}
+	for _, w := range gen.Warnings {
+		log.Println("warning:", w)
+	}
 	log.Println("finished", pwd)
 }
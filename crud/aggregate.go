@@ -0,0 +1,206 @@
+package crud
+
+import (
+	"strings"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/op"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// Aggregate is an aggregate function over a column, selected under an alias to scan it by name
+type Aggregate struct {
+	fn       string
+	col      tab.Column
+	distinct bool
+	alias    string
+}
+
+// CountAll counts the rows of a group, as "count"
+func CountAll() Aggregate { return Aggregate{fn: "COUNT"} }
+
+// CountOf counts the non NULL values of c, as "count_<column>"
+func CountOf(c tab.Column) Aggregate { return Aggregate{fn: "COUNT", col: c} }
+
+// SumOf sums the values of c, as "sum_<column>"
+func SumOf(c tab.Column) Aggregate { return Aggregate{fn: "SUM", col: c} }
+
+// MinOf returns the lowest value of c, as "min_<column>"
+func MinOf(c tab.Column) Aggregate { return Aggregate{fn: "MIN", col: c} }
+
+// MaxOf returns the highest value of c, as "max_<column>"
+func MaxOf(c tab.Column) Aggregate { return Aggregate{fn: "MAX", col: c} }
+
+// AvgOf averages the values of c, as "avg_<column>"
+func AvgOf(c tab.Column) Aggregate { return Aggregate{fn: "AVG", col: c} }
+
+// Distinct returns a copy of a over the distinct values of its column
+func (a Aggregate) Distinct() Aggregate {
+	a.distinct = true
+	return a
+}
+
+// As returns a copy of a selected as alias
+func (a Aggregate) As(alias string) Aggregate {
+	a.alias = alias
+	return a
+}
+
+// Alias returns the name a is selected as
+func (a Aggregate) Alias() string {
+	if len(a.alias) > 0 {
+		return a.alias
+	}
+	if a.col == nil {
+		return strings.ToLower(a.fn)
+	}
+	return strings.ToLower(a.fn) + "_" + a.col.Name()
+}
+
+// Eq matches groups where a equals v, in a Having
+func (a Aggregate) Eq(v interface{}) pred.Predicate { return having{a, "=", v} }
+
+// Neq matches groups where a differs from v, in a Having
+func (a Aggregate) Neq(v interface{}) pred.Predicate { return having{a, "<>", v} }
+
+// Lt matches groups where a is less than v, in a Having
+func (a Aggregate) Lt(v interface{}) pred.Predicate { return having{a, "<", v} }
+
+// Lte matches groups where a is less than or equal to v, in a Having
+func (a Aggregate) Lte(v interface{}) pred.Predicate { return having{a, "<=", v} }
+
+// Gt matches groups where a is greater than v, in a Having
+func (a Aggregate) Gt(v interface{}) pred.Predicate { return having{a, ">", v} }
+
+// Gte matches groups where a is greater than or equal to v, in a Having
+func (a Aggregate) Gte(v interface{}) pred.Predicate { return having{a, ">=", v} }
+
+func (a Aggregate) render(q op.Quoter) string {
+	arg := "*"
+	if a.col != nil {
+		arg = q.Q(a.col)
+	}
+	if a.distinct {
+		arg = "DISTINCT " + arg
+	}
+	return a.fn + "(" + arg + ")"
+}
+
+type having struct {
+	a  Aggregate
+	op string
+	v  interface{}
+}
+
+func (p having) ToSql() (string, []interface{}, error) {
+	return p.Render(dialect.Postgres)
+}
+
+func (p having) Render(d tab.Dialect) (string, []interface{}, error) {
+	return p.a.render(op.For(d)) + " " + p.op + " ?", []interface{}{p.v}, nil
+}
+
+// Group selects the By columns and the Aggregates of the rows matched by Where,
+// keeping the groups matched by Having
+type Group struct {
+	By         []tab.Column
+	Aggregates []Aggregate
+	Where      pred.Predicate
+	Having     pred.Predicate
+	Order      []Order
+}
+
+// Count generates query to count the rows of t matching conditions
+func (b Builder) Count(t tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	return b.GroupBy(t, Group{Aggregates: []Aggregate{CountAll()}, Where: eqs(conditions)})
+}
+
+// CountWhere generates query to count the rows of t matched by where
+func (b Builder) CountWhere(t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	return b.GroupBy(t, Group{Aggregates: []Aggregate{CountAll()}, Where: where})
+}
+
+// Exists generates query to check if a row of t matches conditions
+func (b Builder) Exists(t tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	return exists(b, t, eqs(conditions))
+}
+
+// ExistsWhere generates query to check if a row of t is matched by where
+func (b Builder) ExistsWhere(t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	return exists(b, t, where)
+}
+
+// GroupBy generates query to aggregate the rows of t as described by g
+// Columns are selected by name and aggregates by alias, in that order
+func (b Builder) GroupBy(t tab.Table, g Group) (q tab.Querier, err error) {
+	if len(g.By) < 1 && len(g.Aggregates) < 1 {
+		return nil, tab.QueryGenerationError{Message: "No columns or aggregates to select"}
+	}
+	columns := b.q.Columns(g.By...)
+	for _, a := range g.Aggregates {
+		columns = append(columns, a.render(b.q)+" AS "+b.d.QuoteIdent(a.Alias()))
+	}
+	stmt := b.sb.Select(columns...).
+		From(b.q.Q(t))
+
+	if where := b.visible(t, g.Where); where != nil {
+		stmt = stmt.Where(b.where(where))
+	}
+	if len(g.By) > 0 {
+		stmt = stmt.GroupBy(b.q.Columns(g.By...)...)
+	}
+	if g.Having != nil {
+		stmt = stmt.Having(b.where(g.Having))
+	}
+	for _, o := range g.Order {
+		stmt = stmt.OrderBy(b.d.OrderBy(b.q.Q(o.Column), o.Desc, o.Nulls))
+	}
+
+	sql, args, err := stmt.ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
+}
+
+// Count generates query to count the rows of t matching conditions
+func Count(t tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.Count(t, conditions...)
+}
+
+// CountWhere generates query to count the rows of t matched by where
+func CountWhere(t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	return std.CountWhere(t, where)
+}
+
+// Exists generates query to check if a row of t matches conditions
+func Exists(t tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.Exists(t, conditions...)
+}
+
+// ExistsWhere generates query to check if a row of t is matched by where
+func ExistsWhere(t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	return std.ExistsWhere(t, where)
+}
+
+// GroupBy generates query to aggregate the rows of t as described by g
+func GroupBy(t tab.Table, g Group) (q tab.Querier, err error) {
+	return std.GroupBy(t, g)
+}
+
+func exists(b Builder, t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	stmt := b.sb.Select("1").
+		From(b.q.Q(t))
+
+	if where = b.visible(t, where); where != nil {
+		stmt = stmt.Where(b.where(where))
+	}
+
+	sql, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, "SELECT EXISTS("+sql+")", args)
+}
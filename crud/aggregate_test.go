@@ -0,0 +1,60 @@
+package crud
+
+import (
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+func TestAggregates(t *testing.T) {
+	u := users{ID: 1, Email: "a"}
+	checkQueries(t, []query{
+		q("count", `SELECT COUNT(*) AS "count" FROM "app"."users" WHERE ("email" = $1)`, u.Email)(
+			Count(u, u.Email)),
+		q("count all", `SELECT COUNT(*) AS "count" FROM "app"."users"`)(
+			Count(u)),
+		q("count where", `SELECT COUNT(*) AS "count" FROM "app"."users" WHERE "nick" IS NULL`)(
+			CountWhere(u, pred.IsNull(u.Nick))),
+		q("exists", `SELECT EXISTS(SELECT 1 FROM "app"."users" WHERE ("id" = $1))`, u.ID)(
+			Exists(u, u.ID)),
+		q("exists where", `SELECT EXISTS(SELECT 1 FROM "app"."users" WHERE "id" > $1)`, u.ID)(
+			ExistsWhere(u, pred.Gt(u.ID))),
+		q("group by", `SELECT "email", COUNT(*) AS "count", MAX("id") AS "max_id", COUNT(DISTINCT "nick") AS "nicks" FROM "app"."users" WHERE "id" > $1 GROUP BY "email" HAVING COUNT(*) > $2 ORDER BY "email" ASC`, u.ID, 1)(
+			GroupBy(u, Group{
+				By:         []tab.Column{u.Email},
+				Aggregates: []Aggregate{CountAll(), MaxOf(u.ID), CountOf(u.Nick).Distinct().As("nicks")},
+				Where:      pred.Gt(u.ID),
+				Having:     CountAll().Gt(1),
+				Order:      []Order{Asc(u.Email)},
+			})),
+		q("having group", `SELECT "email" FROM "app"."users" GROUP BY "email" HAVING (SUM("id") >= $1 AND AVG("id") < $2)`, 10, 2.5)(
+			GroupBy(u, Group{By: []tab.Column{u.Email}, Having: pred.And(SumOf(u.ID).Gte(10), AvgOf(u.ID).Lt(2.5))})),
+		q("mysql", "SELECT MIN(`id`) AS `min_id` FROM `app`.`users`")(
+			New(dialect.MySQL).GroupBy(u, Group{Aggregates: []Aggregate{MinOf(u.ID)}})),
+		q("soft delete", `SELECT COUNT(*) AS "count" FROM "posts" WHERE "deleted_at" IS NULL`)(
+			Count(posts{})),
+	})
+	if _, err := GroupBy(u, Group{}); err == nil {
+		t.Error("grouped without columns or aggregates")
+	}
+}
+
+func TestAggregateAlias(t *testing.T) {
+	cases := []struct {
+		a    Aggregate
+		want string
+	}{
+		{CountAll(), "count"},
+		{CountOf(userNick{}), "count_nick"},
+		{SumOf(userID(0)), "sum_id"},
+		{AvgOf(userID(0)).Distinct(), "avg_id"},
+		{MaxOf(userID(0)).As("top"), "top"},
+	}
+	for _, c := range cases {
+		if got := c.a.Alias(); got != c.want {
+			t.Errorf("%+v: got %s, want %s", c.a, got, c.want)
+		}
+	}
+}
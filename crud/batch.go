@@ -0,0 +1,211 @@
+package crud
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/op"
+)
+
+// DefaultMaxParams is the bind parameter limit of postgres and mysql drivers
+const DefaultMaxParams = 65535
+
+// MaxParams returns a copy of the Builder splitting batches to stay within n bind parameters
+func (b Builder) MaxParams(n int) Builder {
+	b.params = n
+	return b
+}
+
+// InsertMany generates queries to insert rows, one multi-row statement per chunk
+// that fits the Builder's bind parameter limit
+// Every row must set the same columns of the same table, in any order
+func (b Builder) InsertMany(rows [][]tab.Column) ([]tab.Querier, error) {
+	return insertMany(b, rows, nil)
+}
+
+// InsertManyR generates queries to insert rows while returning selected columns
+func (b Builder) InsertManyR(rows [][]tab.Column, returning ...tab.Column) ([]tab.Querier, error) {
+	return insertMany(b, rows, nil, returning...)
+}
+
+// UpsertMany generates queries to upsert rows
+// Postgres rejects a DO UPDATE statement touching the same row twice, rows must not repeat a conflict key
+func (b Builder) UpsertMany(rows [][]tab.Column, onConflict tab.Column, update []tab.Column) ([]tab.Querier, error) {
+	on := OnColumns(onConflict).DoUpdate(update...)
+	return insertMany(b, rows, &on)
+}
+
+// UpsertManyR generates queries to upsert rows while returning selected columns
+func (b Builder) UpsertManyR(rows [][]tab.Column, onConflict tab.Column, update []tab.Column, returning ...tab.Column) ([]tab.Querier, error) {
+	on := OnColumns(onConflict).DoUpdate(update...)
+	return insertMany(b, rows, &on, returning...)
+}
+
+// ExecAll runs qs, generated by the Builder, and returns the total of rows affected
+// Run it in a transaction for the chunks of a batch to be atomic
+func (b Builder) ExecAll(ctx context.Context, ex sqlx.ExtContext, qs []tab.Querier) (int64, error) {
+	var total int64
+	for _, q := range qs {
+		res, err := b.Exec(ctx, ex, q)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// InsertManyReturning inserts rows and appends the returning columns of each one to dest, a pointer to a slice
+func (b Builder) InsertManyReturning(ctx context.Context, ex sqlx.ExtContext, dest interface{}, rows [][]tab.Column, returning ...tab.Column) error {
+	qs, err := b.InsertManyR(rows, returning...)
+	if err != nil {
+		return err
+	}
+	for _, q := range qs {
+		if err := b.Rows(ctx, ex, dest, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertMany generates queries to insert rows, one multi-row statement per chunk
+// Every row must set the same columns of the same table, in any order
+func InsertMany(rows [][]tab.Column) ([]tab.Querier, error) {
+	return std.InsertMany(rows)
+}
+
+// InsertManyR generates queries to insert rows while returning selected columns
+func InsertManyR(rows [][]tab.Column, returning ...tab.Column) ([]tab.Querier, error) {
+	return std.InsertManyR(rows, returning...)
+}
+
+// UpsertMany generates queries to upsert rows
+func UpsertMany(rows [][]tab.Column, onConflict tab.Column, update []tab.Column) ([]tab.Querier, error) {
+	return std.UpsertMany(rows, onConflict, update)
+}
+
+// UpsertManyR generates queries to upsert rows while returning selected columns
+func UpsertManyR(rows [][]tab.Column, onConflict tab.Column, update []tab.Column, returning ...tab.Column) ([]tab.Querier, error) {
+	return std.UpsertManyR(rows, onConflict, update, returning...)
+}
+
+// ExecAll runs qs, generated by the default builder, and returns the total of rows affected
+func ExecAll(ctx context.Context, ex sqlx.ExtContext, qs []tab.Querier) (int64, error) {
+	return std.ExecAll(ctx, ex, qs)
+}
+
+// InsertManyReturning inserts rows and appends the returning columns of each one to dest, a pointer to a slice
+func InsertManyReturning(ctx context.Context, ex sqlx.ExtContext, dest interface{}, rows [][]tab.Column, returning ...tab.Column) error {
+	return std.InsertManyReturning(ctx, ex, dest, rows, returning...)
+}
+
+// sameRows checks rows target the same table and columns and returns them in the order of the first row
+func sameRows(rows [][]tab.Column) ([][]tab.Column, error) {
+	if len(rows) < 1 || len(rows[0]) < 1 {
+		return nil, tab.QueryGenerationError{Message: "No columns to insert"}
+	}
+	first := rows[0]
+	t := first[0].Table()
+	same := make([][]tab.Column, 0, len(rows))
+	for i, row := range rows {
+		for _, c := range row {
+			if !sameTable(c.Table(), t) {
+				return nil, tab.QueryGenerationError{Message: "row " + strconv.Itoa(i) + " column " + c.Name() + " is not in table " + t.Name()}
+			}
+		}
+		ordered := op.InOrder(row, first)
+		if len(row) != len(first) || len(ordered) != len(first) {
+			return nil, tab.QueryGenerationError{Message: "row " + strconv.Itoa(i) + " sets different columns than row 0"}
+		}
+		same = append(same, ordered)
+	}
+	return same, nil
+}
+
+func insertMany(b Builder, rows [][]tab.Column, on *OnConflict, returning ...tab.Column) ([]tab.Querier, error) {
+	rows, err := sameRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	// the errors of the first invalid row
+	for _, row := range rows {
+		if err := b.validate(row); err != nil {
+			return nil, err
+		}
+	}
+	t := rows[0][0].Table()
+	columns := b.q.Columns(rows[0]...)
+
+	suffixes := []string{}
+	var suffixArgs []interface{}
+	if on != nil {
+		cft, args, err := b.conflict(*on)
+		if err != nil {
+			return nil, err
+		}
+		suffixes = append(suffixes, cft)
+		suffixArgs = args
+	}
+	if len(returning) > 0 {
+		rtn, err := b.returning(returning)
+		if err != nil {
+			return nil, err
+		}
+		suffixes = append(suffixes, rtn)
+	}
+	suffix := strings.Join(suffixes, " ")
+
+	per := (b.maxParams() - len(suffixArgs)) / len(columns)
+	if per < 1 {
+		return nil, tab.QueryGenerationError{Message: "a single row has more columns than bind parameters allowed"}
+	}
+	qs := []tab.Querier{}
+	for start := 0; start < len(rows); start += per {
+		end := start + per
+		if end > len(rows) {
+			end = len(rows)
+		}
+		stmt := b.sb.Insert(b.q.Q(t)).
+			Columns(columns...)
+		for _, row := range rows[start:end] {
+			values := make([]interface{}, 0, len(row))
+			for _, v := range row {
+				values = append(values, v)
+			}
+			stmt = stmt.Values(values...)
+		}
+		if len(suffix) > 0 {
+			stmt = stmt.Suffix(suffix, suffixArgs...)
+		}
+		sql, args, err := stmt.ToSql()
+		if err != nil {
+			return nil, err
+		}
+		q, err := b.query(t, sql, args)
+		if err != nil {
+			return nil, err
+		}
+		qs = append(qs, q)
+	}
+	return qs, nil
+}
+
+func (b Builder) maxParams() int {
+	if b.params < 1 {
+		return DefaultMaxParams
+	}
+	return b.params
+}
+
+// sameTable compares tables by schema and name
+func sameTable(a, b tab.Table) bool {
+	return op.Q(a) == op.Q(b)
+}
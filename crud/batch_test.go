@@ -0,0 +1,132 @@
+package crud
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+func userRows(n int) [][]tab.Column {
+	rows := [][]tab.Column{}
+	for i := 1; i <= n; i++ {
+		rows = append(rows, []tab.Column{userID(i), userEmail(string(rune('a' + i - 1)))})
+	}
+	return rows
+}
+
+func TestInsertMany(t *testing.T) {
+	qs, err := InsertMany(userRows(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 1 {
+		t.Fatalf("got %d queries, want 1", len(qs))
+	}
+	checkQueries(t, []query{
+		q("insert many", `INSERT INTO "app"."users" ("id","email") VALUES ($1,$2),($3,$4),($5,$6)`,
+			userID(1), userEmail("a"), userID(2), userEmail("b"), userID(3), userEmail("c"))(qs[0], nil),
+	})
+}
+
+func TestInsertManyChunks(t *testing.T) {
+	// 5 parameters fit 2 rows of 2 columns
+	qs, err := std.MaxParams(5).InsertManyR(userRows(5), userID(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 3 {
+		t.Fatalf("got %d queries, want 3", len(qs))
+	}
+	checkQueries(t, []query{
+		q("chunk 1", `INSERT INTO "app"."users" ("id","email") VALUES ($1,$2),($3,$4) RETURNING "id"`,
+			userID(1), userEmail("a"), userID(2), userEmail("b"))(qs[0], nil),
+		q("chunk 2", `INSERT INTO "app"."users" ("id","email") VALUES ($1,$2),($3,$4) RETURNING "id"`,
+			userID(3), userEmail("c"), userID(4), userEmail("d"))(qs[1], nil),
+		q("chunk 3", `INSERT INTO "app"."users" ("id","email") VALUES ($1,$2) RETURNING "id"`,
+			userID(5), userEmail("e"))(qs[2], nil),
+	})
+
+	// the parameters of the conflict clause count in every chunk
+	on := OnColumns(userEmail("")).DoUpdate(userNick{}).UpdateIf(pred.Gt(userID(0)))
+	qs, err = std.MaxParams(5).UpsertManyOn(userRows(3), on)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 2 {
+		t.Errorf("got %d queries, want 2 with the condition's parameter", len(qs))
+	}
+}
+
+func TestUpsertMany(t *testing.T) {
+	rows := [][]tab.Column{
+		{userID(1), userEmail("a")},
+		// columns are reordered as in the first row
+		{userEmail("b"), userID(2)},
+	}
+	qs, err := UpsertMany(rows, userEmail(""), []tab.Column{userID(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkQueries(t, []query{
+		q("upsert many", `INSERT INTO "app"."users" ("id","email") VALUES ($1,$2),($3,$4) ON CONFLICT ("email") DO UPDATE SET "id" = EXCLUDED."id"`,
+			userID(1), userEmail("a"), userID(2), userEmail("b"))(qs[0], nil),
+	})
+}
+
+func TestInsertManyErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		b    Builder
+		rows [][]tab.Column
+	}{
+		{"no rows", std, nil},
+		{"no columns", std, [][]tab.Column{{}}},
+		{"other columns", std, [][]tab.Column{{userID(1), userEmail("a")}, {userID(2), userNick{}}}},
+		{"fewer columns", std, [][]tab.Column{{userID(1), userEmail("a")}, {userID(2)}}},
+		{"other table", std, [][]tab.Column{{userID(1)}, {accountID(2)}}},
+		{"too many columns", std.MaxParams(1), userRows(1)},
+	}
+	for _, c := range cases {
+		if _, err := c.b.InsertMany(c.rows); err == nil {
+			t.Errorf("%s: got no error", c.name)
+		} else if _, ok := err.(tab.QueryGenerationError); !ok {
+			t.Errorf("%s: got %v, want a QueryGenerationError", c.name, err)
+		}
+	}
+}
+
+func TestExecAll(t *testing.T) {
+	f := &fakeDB{affected: 2}
+	qs, err := std.MaxParams(4).InsertMany(userRows(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := ExecAll(context.Background(), open(f), qs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 || len(f.statements()) != 2 {
+		t.Errorf("got %d rows affected by %d statements, want 4 by 2", n, len(f.statements()))
+	}
+	if args := f.args[1]; len(args) != 2 || args[0] != driver.Value(int64(3)) {
+		t.Errorf("got args %v for the second chunk", args)
+	}
+}
+
+// accounts is a table of the default schema
+type accounts struct{}
+
+func (accounts) Name() string                  { return "accounts" }
+func (accounts) Constraints() []tab.Constraint { return nil }
+func (accounts) Columns() []tab.Column         { return []tab.Column{accountID(0)} }
+
+type accountID int64
+
+func (accountID) Name() string                   { return "id" }
+func (accountID) Table() tab.Table               { return accounts{} }
+func (accountID) SQLType() string                { return "int8,bigint" }
+func (accountID) NonNull() bool                  { return true }
+func (c accountID) Value() (driver.Value, error) { return int64(c), nil }
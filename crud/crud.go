@@ -3,303 +3,581 @@ package crud
 import (
 	sq "github.com/elgris/sqrl"
 	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
 	"github.com/pindamonhangaba/tabua/op"
-	"strings"
+	"github.com/pindamonhangaba/tabua/pred"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
+	"time"
 )
 
-type qSB struct {
-	sq.StatementBuilderType
+// Builder generates queries quoted and bound for a Dialect
+type Builder struct {
+	d       tab.Dialect
+	q       op.Quoter
+	sb      sq.StatementBuilderType
+	timeout time.Duration
+	params  int
+	deleted visibility
+	hard    bool
+	all     bool
+	valid   bool
+	hooks   tab.Hooks
+	cache   *StmtCache
+	tags    sqlcomment.Tags
 }
 
-// Q return an sq.StatementBuilderType with sq.Question (?) as placeholder
-var Q qSB
+// New returns a Builder generating queries for d
+func New(d tab.Dialect) Builder {
+	return Builder{
+		d:  d,
+		q:  op.For(d),
+		sb: sq.StatementBuilder.PlaceholderFormat(dialect.Format{Dialect: d}),
+	}
+}
+
+// Q is a postgres Builder with sq.Question (?) as placeholder
+var Q = New(dialect.Question(dialect.Postgres))
+
+// Default Builder is postgres with sq.Dollar ($#) as placeholder
+var std = New(dialect.Postgres)
 
-// Default placeholder is sq.Dollar ($#)
-var builder sq.StatementBuilderType
+// AllRows returns a copy of the Builder allowing UPDATE and DELETE statements without conditions,
+// which are refused with tabua.UnconditionalError otherwise
+func (b Builder) AllRows() Builder {
+	b.all = true
+	return b
+}
 
-func init() {
-	Q = qSB{sq.StatementBuilder.PlaceholderFormat(sq.Question)}
-	builder = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+// AllRows returns the default Builder allowing UPDATE and DELETE statements without conditions
+func AllRows() Builder {
+	return std.AllRows()
 }
 
-func (qs qSB) Select(cols []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
-	return selectOnly(qs.StatementBuilderType, cols, conditions...)
+// Validate returns a copy of the Builder checking the columns of INSERT and UPDATE statements
+// with tabua.ValidateColumns, their tabua.ValidationErrors are returned instead of a query
+func (b Builder) Validate() Builder {
+	b.valid = true
+	return b
 }
 
-func (qs qSB) Insert(cols []tab.Column) (q tab.Query, err error) {
-	return insertOnly(qs.StatementBuilderType, cols)
+// Validate returns the default Builder checking the columns of INSERT and UPDATE statements
+func Validate() Builder {
+	return std.Validate()
 }
 
-func (qs qSB) Upsert(cols []tab.Column, conflict tab.Column, update []tab.Column) (q tab.Query, err error) {
-	return upsertOnly(qs.StatementBuilderType, cols, conflict, update)
+// validate checks cols when the Builder validates them
+func (b Builder) validate(cols []tab.Column) error {
+	if !b.valid {
+		return nil
+	}
+	return tab.ValidateColumns(cols...)
 }
 
-func (qs qSB) InsertR(cols []tab.Column, returning ...tab.Column) (q tab.Query, err error) {
-	return insertOnlyR(qs.StatementBuilderType, cols, returning...)
+// Comment returns a copy of the Builder tagging every statement with a sqlcommenter comment
+// of tags and the table, which stays the same across calls for statement caches
+// Add the tags of a request's context with a sqlcomment.Hook
+func (b Builder) Comment(tags sqlcomment.Tags) Builder {
+	b.tags = b.tags.Merge(tags)
+	return b
 }
 
-func (qs qSB) Update(cols []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
-	return update(qs.StatementBuilderType, cols, conditions...)
+// comment appends the Builder's tags to sql generated for t
+func (b Builder) comment(t tab.Table, sql string) string {
+	if b.tags == nil {
+		return sql
+	}
+	return sqlcomment.Append(sql, sqlcomment.Table(t).Merge(b.tags))
 }
 
-func (qs qSB) Delete(t tab.Table, conditions ...tab.Column) (q tab.Query, err error) {
-	return delete(qs.StatementBuilderType, t, conditions...)
+// query returns the statement generated for t, commented and passed through the Generate hooks
+func (b Builder) query(t tab.Table, sql string, args []interface{}) (tab.Querier, error) {
+	return b.chain().Generate(tab.Sq{S: b.comment(t, sql), A: args})
+}
+
+// Dialect returns the Dialect queries are generated for
+func (b Builder) Dialect() tab.Dialect {
+	return b.d
 }
 
 // Select generates query to select only cols columns, given conditions
 // The table is defined by the first column
-func Select(cols []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
-	return selectOnly(builder, cols, conditions...)
+func (b Builder) Select(cols []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return selectOnly(b, cols, eqs(conditions), Page{})
+}
+
+// SelectWhere generates query to select only cols columns filtered by where
+// The table is defined by the first column
+func (b Builder) SelectWhere(cols []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return selectOnly(b, cols, where, Page{})
 }
 
 // Insert generates query to insert table
-func Insert(cols []tab.Column) (q tab.Query, err error) {
-	return insertOnly(builder, cols)
+func (b Builder) Insert(cols []tab.Column) (q tab.Querier, err error) {
+	return insertOnly(b, cols)
 }
 
 // Upsert generates query to upsert table
-func Upsert(cols []tab.Column, onConflict tab.Column, update []tab.Column) (q tab.Query, err error) {
-	return upsertOnly(builder, cols, onConflict, update)
+func (b Builder) Upsert(cols []tab.Column, onConflict tab.Column, update []tab.Column) (q tab.Querier, err error) {
+	return upsertOnly(b, cols, OnColumns(onConflict).DoUpdate(update...))
 }
 
 // InsertR generates query to insert columns while returning selected columns
 // The table is defined by the first column
-func InsertR(cols []tab.Column, returning ...tab.Column) (q tab.Query, err error) {
-	return insertOnlyR(builder, cols, returning...)
+func (b Builder) InsertR(cols []tab.Column, returning ...tab.Column) (q tab.Querier, err error) {
+	return insertOnlyR(b, cols, returning...)
 }
 
 // Update generates query to update a table given conditions
-func Update(cols []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
-	return update(builder, cols, conditions...)
+// Updates of a tabua.Versioned table bump its version, and setting the version column
+// only updates rows still at that version, executors return ErrStaleRow otherwise
+func (b Builder) Update(cols []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return update(b, cols, eqs(conditions), conditions)
 }
 
 // UpdateR generates query to update a table given conditions
-func UpdateR(cols []tab.Column, returning []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
-	return updateR(builder, cols, returning, conditions...)
+func (b Builder) UpdateR(cols []tab.Column, returning []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return updateR(b, cols, returning, eqs(conditions), conditions)
+}
+
+// UpdateWhere generates query to update the rows of a table matched by where
+func (b Builder) UpdateWhere(cols []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return update(b, cols, where, nil)
+}
+
+// UpdateWhereR generates query to update the rows matched by where and return selected columns
+func (b Builder) UpdateWhereR(cols []tab.Column, returning []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return updateR(b, cols, returning, where, nil)
 }
 
 // Delete generates query to remove entry given conditions
-func Delete(t tab.Table, conditions ...tab.Column) (q tab.Query, err error) {
-	return delete(builder, t, conditions...)
+// Rows of a tabua.SoftDeleter table are marked deleted instead, unless the Builder does HardDelete
+func (b Builder) Delete(t tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	return deleteOnly(b, t, eqs(conditions))
 }
 
 // DeleteR generates query to remove entry given conditions and return selected columns
-func DeleteR(t tab.Table, returning []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
-	return deleteR(builder, t, returning, conditions...)
+func (b Builder) DeleteR(t tab.Table, returning []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return deleteOnlyR(b, t, returning, eqs(conditions))
+}
+
+// DeleteWhere generates query to remove the rows matched by where
+func (b Builder) DeleteWhere(t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	return deleteOnly(b, t, where)
+}
+
+// DeleteWhereR generates query to remove the rows matched by where and return selected columns
+func (b Builder) DeleteWhereR(t tab.Table, returning []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return deleteOnlyR(b, t, returning, where)
+}
+
+// Select generates query to select only cols columns, given conditions
+// The table is defined by the first column
+func Select(cols []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.Select(cols, conditions...)
+}
+
+// SelectWhere generates query to select only cols columns filtered by where
+// The table is defined by the first column
+func SelectWhere(cols []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return std.SelectWhere(cols, where)
+}
+
+// Insert generates query to insert table
+func Insert(cols []tab.Column) (q tab.Querier, err error) {
+	return std.Insert(cols)
 }
 
-func selectOnly(builder sq.StatementBuilderType, cols []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
+// Upsert generates query to upsert table
+func Upsert(cols []tab.Column, onConflict tab.Column, update []tab.Column) (q tab.Querier, err error) {
+	return std.Upsert(cols, onConflict, update)
+}
+
+// InsertR generates query to insert columns while returning selected columns
+// The table is defined by the first column
+func InsertR(cols []tab.Column, returning ...tab.Column) (q tab.Querier, err error) {
+	return std.InsertR(cols, returning...)
+}
+
+// Update generates query to update a table given conditions
+func Update(cols []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.Update(cols, conditions...)
+}
+
+// UpdateR generates query to update a table given conditions
+func UpdateR(cols []tab.Column, returning []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.UpdateR(cols, returning, conditions...)
+}
+
+// UpdateWhere generates query to update the rows of a table matched by where
+func UpdateWhere(cols []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return std.UpdateWhere(cols, where)
+}
+
+// UpdateWhereR generates query to update the rows matched by where and return selected columns
+func UpdateWhereR(cols []tab.Column, returning []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return std.UpdateWhereR(cols, returning, where)
+}
+
+// Delete generates query to remove entry given conditions
+func Delete(t tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.Delete(t, conditions...)
+}
+
+// DeleteR generates query to remove entry given conditions and return selected columns
+func DeleteR(t tab.Table, returning []tab.Column, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.DeleteR(t, returning, conditions...)
+}
+
+// DeleteWhere generates query to remove the rows matched by where
+func DeleteWhere(t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	return std.DeleteWhere(t, where)
+}
+
+// DeleteWhereR generates query to remove the rows matched by where and return selected columns
+func DeleteWhereR(t tab.Table, returning []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	return std.DeleteWhereR(t, returning, where)
+}
+
+// eqs turns condition columns into an equality predicate, nil when there are none
+func eqs(conditions []tab.Column) pred.Predicate {
+	if len(conditions) < 1 {
+		return nil
+	}
+	return pred.Eqs(conditions...)
+}
+
+// and joins p to where, which may be nil
+func and(where, p pred.Predicate) pred.Predicate {
+	if where == nil {
+		return p
+	}
+	return pred.And(where, p)
+}
+
+// rendered renders a predicate for the Builder's dialect
+type rendered struct {
+	p pred.Predicate
+	d tab.Dialect
+}
+
+func (r rendered) ToSql() (string, []interface{}, error) {
+	return r.p.Render(r.d)
+}
+
+func (b Builder) where(p pred.Predicate) sq.Sqlizer {
+	return rendered{p, b.d}
+}
+
+// guard refuses a statement changing every row of t, unless the Builder allows AllRows.
+// A where of only empty groups renders as a constant and doesn't count as a condition
+func (b Builder) guard(statement string, t tab.Table, where pred.Predicate) error {
+	if pred.Empty(where) && !b.all {
+		return tab.UnconditionalError{Statement: statement, Table: t.Name()}
+	}
+	return nil
+}
+
+// returning renders the RETURNING clause for cols
+func (b Builder) returning(cols []tab.Column) (string, error) {
+	if !b.d.Returning() {
+		return "", tab.QueryGenerationError{Message: b.d.Name() + " does not support RETURNING"}
+	}
+	return "RETURNING " + b.q.Join(cols...), nil
+}
+
+func selectOnly(b Builder, cols []tab.Column, where pred.Predicate, p Page) (q tab.Querier, err error) {
 	if len(cols) < 1 {
-		return nil, tab.QueryGenerationError{"No columns to select"}
+		return nil, tab.QueryGenerationError{Message: "No columns to select"}
 	}
 	t := cols[0].Table()
 
 	var columns []string
 	for _, c := range cols {
-		columns = append(columns, op.Q(c))
+		columns = append(columns, b.q.Q(c))
 	}
-	stmt := builder.Select(columns...).
-		From(op.Q(t))
+	stmt := b.sb.Select(columns...).
+		From(b.q.Q(t))
+
+	where = b.visible(t, where)
 
-	if len(conditions) > 0 {
-		where := sq.Eq{}
-		for _, cond := range conditions {
-			where[op.Q(cond)] = cond
+	order, err := p.order(t)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.After) > 0 {
+		after, err := seek(p.After, order)
+		if err != nil {
+			return nil, err
 		}
-		stmt = stmt.Where(where)
+		where = and(where, after)
+	}
+
+	if where != nil {
+		stmt = stmt.Where(b.where(where))
+	}
+
+	for _, o := range order {
+		stmt = stmt.OrderBy(b.d.OrderBy(b.q.Q(o.Column), o.Desc, o.Nulls))
+	}
+	if limit := b.d.Limit(p.Limit, p.Offset); len(limit) > 0 {
+		stmt = stmt.Suffix(limit)
 	}
 
 	sql, args, err := stmt.ToSql()
 
-	return tab.Sq{sql, args}, err
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
 }
 
-func insertOnly(builder sq.StatementBuilderType, cols []tab.Column, returning ...tab.Column) (q tab.Query, err error) {
+func insertOnly(b Builder, cols []tab.Column, returning ...tab.Column) (q tab.Querier, err error) {
 	if len(cols) < 1 {
-		return nil, tab.QueryGenerationError{"No columns to insert"}
+		return nil, tab.QueryGenerationError{Message: "No columns to insert"}
+	}
+	if err := b.validate(cols); err != nil {
+		return nil, err
 	}
 	t := cols[0].Table()
 
 	var columns []string
 	var values []interface{}
 	for _, v := range cols {
-		columns = append(columns, op.Q(v))
+		columns = append(columns, b.q.Q(v))
 		values = append(values, v)
 	}
-	stmt := builder.Insert(op.Q(t)).
+	stmt := b.sb.Insert(b.q.Q(t)).
 		Columns(columns...).
 		Values(values...)
 
 	sql, args, err := stmt.ToSql()
 
-	return tab.Sq{sql, args}, err
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
 }
 
-func upsertOnly(builder sq.StatementBuilderType, cols []tab.Column, onConflict tab.Column, update []tab.Column, returning ...tab.Column) (q tab.Query, err error) {
+func upsertOnly(b Builder, cols []tab.Column, on OnConflict, returning ...tab.Column) (q tab.Querier, err error) {
 	if len(cols) < 1 {
-		return nil, tab.QueryGenerationError{"No columns to insert"}
+		return nil, tab.QueryGenerationError{Message: "No columns to insert"}
+	}
+	if err := b.validate(cols); err != nil {
+		return nil, err
 	}
 	t := cols[0].Table()
 
 	var columns []string
 	var values []interface{}
 	for _, v := range cols {
-		columns = append(columns, op.Q(v))
+		columns = append(columns, b.q.Q(v))
 		values = append(values, v)
 	}
-	stmt := builder.Insert(op.Q(t)).
+	stmt := b.sb.Insert(b.q.Q(t)).
 		Columns(columns...).
 		Values(values...)
 
-	cft := onConflict.Name()
-	upd := []string{}
-	for _, col := range update {
-		upd = append(upd, col.Name()+"= EXCLUDED."+col.Name())
+	stmtConf, confArgs, err := b.conflict(on)
+	if err != nil {
+		return nil, err
 	}
+	stmt = stmt.Suffix(stmtConf, confArgs...)
 
-	stmtConf := "ON CONFLICT (" + cft + ") DO NOTHING"
-	if len(update) > 0 {
-		stmtConf = " ON CONFLICT (" + cft + ") DO UPDATE SET " +
-			strings.Join(upd, ", ")
+	if len(returning) > 0 {
+		rtn, err := b.returning(returning)
+		if err != nil {
+			return nil, err
+		}
+		stmt = stmt.Suffix(rtn)
 	}
-	stmt = stmt.Suffix(stmtConf)
 
 	sql, args, err := stmt.ToSql()
 
-	return tab.Sq{sql, args}, err
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
 }
 
-func insertOnlyR(builder sq.StatementBuilderType, cols []tab.Column, returning ...tab.Column) (q tab.Query, err error) {
+func insertOnlyR(b Builder, cols []tab.Column, returning ...tab.Column) (q tab.Querier, err error) {
 	if len(cols) < 1 {
-		return nil, tab.QueryGenerationError{"No columns to insert"}
+		return nil, tab.QueryGenerationError{Message: "No columns to insert"}
+	}
+	if err := b.validate(cols); err != nil {
+		return nil, err
 	}
 	t := cols[0].Table()
 
 	var columns []string
 	var values []interface{}
 	for _, v := range cols {
-		columns = append(columns, op.Q(v))
+		columns = append(columns, b.q.Q(v))
 		values = append(values, v)
 	}
-	stmt := builder.Insert(op.Q(t)).
+	stmt := b.sb.Insert(b.q.Q(t)).
 		Columns(columns...).
 		Values(values...)
 
 	if len(returning) > 0 {
-		rtn := ""
-		for _, r := range returning {
-			rtn += op.Q(r) + ","
+		rtn, err := b.returning(returning)
+		if err != nil {
+			return nil, err
 		}
-		rtnR := []rune(rtn)
-		rtnR = rtnR[0 : len(rtnR)-1]
-		stmt = stmt.Suffix("RETURNING " + string(rtnR))
+		stmt = stmt.Suffix(rtn)
 	}
 
 	sql, args, err := stmt.ToSql()
 
-	return tab.Sq{sql, args}, err
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
 }
 
-func update(builder sq.StatementBuilderType, columns []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
+func update(b Builder, columns []tab.Column, where pred.Predicate, key []tab.Column) (q tab.Querier, err error) {
 	if len(columns) < 1 {
-		return nil, tab.QueryGenerationError{"No columns to select"}
+		return nil, tab.QueryGenerationError{Message: "No columns to select"}
 	}
 	t := columns[0].Table()
+	if err := b.guard("UPDATE", t, where); err != nil {
+		return nil, err
+	}
+	if err := b.validate(columns); err != nil {
+		return nil, err
+	}
 
-	stmt := builder.Update(op.Q(t))
+	stmt := b.sb.Update(b.q.Q(t))
 
+	columns, lk, err := version(b, t, columns, key)
+	if err != nil {
+		return nil, err
+	}
 	for _, v := range columns {
-		stmt = stmt.Set(op.Q(v), v)
+		stmt = stmt.Set(b.q.Q(v), v)
 	}
-
-	if len(conditions) > 0 {
-		where := sq.Eq{}
-		for _, cond := range conditions {
-			where[op.Q(cond)] = cond
+	if lk != nil {
+		stmt = stmt.Set(b.q.Q(lk.col), lk.bump)
+		if lk.check != nil {
+			where = and(where, lk.check)
 		}
-		stmt = stmt.Where(where)
 	}
 
-	sql, args, err := stmt.ToSql()
+	where = b.visible(t, where)
+	if where != nil {
+		stmt = stmt.Where(b.where(where))
+	}
 
-	return tab.Sq{sql, args}, err
+	sql, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if q, err = b.query(t, sql, args); err != nil {
+		return nil, err
+	}
+	return staleQuery(q, lk, t, key), nil
 }
 
-func updateR(builder sq.StatementBuilderType, columns []tab.Column, returning []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
+func updateR(b Builder, columns []tab.Column, returning []tab.Column, where pred.Predicate, key []tab.Column) (q tab.Querier, err error) {
 	if len(columns) < 1 {
-		return nil, tab.QueryGenerationError{"No columns to select"}
+		return nil, tab.QueryGenerationError{Message: "No columns to select"}
 	}
 	t := columns[0].Table()
+	if err := b.guard("UPDATE", t, where); err != nil {
+		return nil, err
+	}
+	if err := b.validate(columns); err != nil {
+		return nil, err
+	}
 
-	stmt := builder.Update(op.Q(t))
+	stmt := b.sb.Update(b.q.Q(t))
 
+	columns, lk, err := version(b, t, columns, key)
+	if err != nil {
+		return nil, err
+	}
 	for _, v := range columns {
-		stmt = stmt.Set(op.Q(v), v)
+		stmt = stmt.Set(b.q.Q(v), v)
 	}
-
-	if len(conditions) > 0 {
-		where := sq.Eq{}
-		for _, cond := range conditions {
-			where[op.Q(cond)] = cond
+	if lk != nil {
+		stmt = stmt.Set(b.q.Q(lk.col), lk.bump)
+		if lk.check != nil {
+			where = and(where, lk.check)
 		}
-		stmt = stmt.Where(where)
+	}
+
+	where = b.visible(t, where)
+	if where != nil {
+		stmt = stmt.Where(b.where(where))
 	}
 
 	if len(returning) > 0 {
-		rtn := ""
-		for _, r := range returning {
-			rtn += op.Q(r) + ","
+		rtn, err := b.returning(returning)
+		if err != nil {
+			return nil, err
 		}
-		rtnR := []rune(rtn)
-		rtnR = rtnR[0 : len(rtnR)-1]
-		stmt = stmt.Suffix("RETURNING " + string(rtnR))
+		stmt = stmt.Suffix(rtn)
 	}
 
 	sql, args, err := stmt.ToSql()
-
-	return tab.Sq{sql, args}, err
+	if err != nil {
+		return nil, err
+	}
+	if q, err = b.query(t, sql, args); err != nil {
+		return nil, err
+	}
+	return staleQuery(q, lk, t, key), nil
 }
 
-func delete(builder sq.StatementBuilderType, t tab.Table, conditions ...tab.Column) (q tab.Query, err error) {
+func deleteOnly(b Builder, t tab.Table, where pred.Predicate) (q tab.Querier, err error) {
+	if err := b.guard("DELETE", t, where); err != nil {
+		return nil, err
+	}
+	if sd, ok := t.(tab.SoftDeleter); ok && !b.hard {
+		return softDelete(b, t, sd, where, nil)
+	}
 
-	stmt := builder.Delete(op.Q(t))
+	stmt := b.sb.Delete(b.q.Q(t))
 
-	if len(conditions) > 0 {
-		where := sq.Eq{}
-		for _, cond := range conditions {
-			where[op.Q(cond)] = cond
-		}
-		stmt = stmt.Where(where)
+	where = b.removable(t, where)
+	if where != nil {
+		stmt = stmt.Where(b.where(where))
 	}
 
 	sql, args, err := stmt.ToSql()
 
-	return tab.Sq{sql, args}, err
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
 }
 
-func deleteR(builder sq.StatementBuilderType, t tab.Table, returning []tab.Column, conditions ...tab.Column) (q tab.Query, err error) {
+func deleteOnlyR(b Builder, t tab.Table, returning []tab.Column, where pred.Predicate) (q tab.Querier, err error) {
+	if err := b.guard("DELETE", t, where); err != nil {
+		return nil, err
+	}
+	if sd, ok := t.(tab.SoftDeleter); ok && !b.hard {
+		return softDelete(b, t, sd, where, returning)
+	}
 
-	stmt := builder.Delete(op.Q(t))
+	stmt := b.sb.Delete(b.q.Q(t))
 
-	if len(conditions) > 0 {
-		where := sq.Eq{}
-		for _, cond := range conditions {
-			where[op.Q(cond)] = cond
-		}
-		stmt = stmt.Where(where)
+	where = b.removable(t, where)
+	if where != nil {
+		stmt = stmt.Where(b.where(where))
 	}
 
 	if len(returning) > 0 {
-		rtn := ""
-		for _, r := range returning {
-			rtn += op.Q(r) + ","
+		rtn, err := b.returning(returning)
+		if err != nil {
+			return nil, err
 		}
-		rtnR := []rune(rtn)
-		rtnR = rtnR[0 : len(rtnR)-1]
-		stmt = stmt.Suffix("RETURNING " + string(rtnR))
+		stmt = stmt.Suffix(rtn)
 	}
 
 	sql, args, err := stmt.ToSql()
 
-	return tab.Sq{sql, args}, err
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
 }
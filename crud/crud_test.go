@@ -0,0 +1,188 @@
+package crud
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// users is a table of the app schema with a primary key
+type users struct {
+	ID    userID    `db:"id"`
+	Email userEmail `db:"email"`
+	Nick  userNick  `db:"nick"`
+}
+
+func (users) Name() string                    { return "users" }
+func (users) Schema() string                  { return "app" }
+func (users) Constraints() []tab.Constraint   { return []tab.Constraint{"users_pkey"} }
+func (u users) Columns() []tab.Column         { return []tab.Column{u.ID, u.Email, u.Nick} }
+func (users) Constrainers() []tab.Constrainer { return []tab.Constrainer{usersPK{}, usersEmailKey{}} }
+
+type userID int64
+
+func (userID) Name() string                   { return "id" }
+func (userID) Table() tab.Table               { return users{} }
+func (userID) SQLType() string                { return "int8,bigint" }
+func (userID) NonNull() bool                  { return true }
+func (c userID) Value() (driver.Value, error) { return int64(c), nil }
+
+type userEmail string
+
+func (userEmail) Name() string                   { return "email" }
+func (userEmail) Table() tab.Table               { return users{} }
+func (userEmail) SQLType() string                { return "text,text" }
+func (userEmail) NonNull() bool                  { return true }
+func (c userEmail) Value() (driver.Value, error) { return string(c), nil }
+
+type userNick struct{ sql.NullString }
+
+func (userNick) Name() string     { return "nick" }
+func (userNick) Table() tab.Table { return users{} }
+func (userNick) SQLType() string  { return "text,text" }
+func (userNick) NonNull() bool    { return false }
+
+func nick(s string) userNick {
+	return userNick{sql.NullString{String: s, Valid: true}}
+}
+
+type usersPK struct{}
+
+func (usersPK) Name() string             { return "users_pkey" }
+func (usersPK) Type() tab.ConstraintType { return tab.ConstraintPK }
+func (usersPK) Definition() string       { return "PRIMARY KEY (id)" }
+func (usersPK) Keys() []tab.Column       { return []tab.Column{userID(0)} }
+
+type usersEmailKey struct{}
+
+func (usersEmailKey) Name() string             { return "users_email_key" }
+func (usersEmailKey) Type() tab.ConstraintType { return tab.ConstraintUnique }
+func (usersEmailKey) Definition() string       { return "UNIQUE (email)" }
+func (usersEmailKey) Uniques() []tab.Column    { return []tab.Column{userEmail("")} }
+
+// query is the expected output of a generation function
+type query struct {
+	name string
+	q    tab.Querier
+	err  error
+	sql  string
+	args []interface{}
+}
+
+func checkQueries(t *testing.T, qs []query) {
+	t.Helper()
+	for _, c := range qs {
+		if c.err != nil {
+			t.Errorf("%s: %v", c.name, c.err)
+			continue
+		}
+		if c.q.SQL() != c.sql {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, c.q.SQL(), c.sql)
+		}
+		if len(c.q.Args()) > 0 || len(c.args) > 0 {
+			if !reflect.DeepEqual(c.q.Args(), c.args) {
+				t.Errorf("%s: got args %v, want %v", c.name, c.q.Args(), c.args)
+			}
+		}
+	}
+}
+
+func q(name string, sql string, args ...interface{}) func(tab.Querier, error) query {
+	return func(qr tab.Querier, err error) query {
+		return query{name: name, q: qr, err: err, sql: sql, args: args}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	u := users{ID: 1, Email: "a@b.c"}
+	checkQueries(t, []query{
+		q("select", `SELECT "id", "email" FROM "app"."users" WHERE ("id" = $1)`, u.ID)(
+			Select([]tab.Column{u.ID, u.Email}, u.ID)),
+		q("select where", `SELECT "id" FROM "app"."users" WHERE ("id" > $1 OR "nick" IS NULL)`, u.ID)(
+			SelectWhere([]tab.Column{u.ID}, pred.Or(pred.Gt(u.ID), pred.IsNull(u.Nick)))),
+		q("insert", `INSERT INTO "app"."users" ("id","email") VALUES ($1,$2)`, u.ID, u.Email)(
+			Insert([]tab.Column{u.ID, u.Email})),
+		q("insert returning", `INSERT INTO "app"."users" ("email") VALUES ($1) RETURNING "id"`, u.Email)(
+			InsertR([]tab.Column{u.Email}, u.ID)),
+		q("update", `UPDATE "app"."users" SET "email" = $1 WHERE ("id" = $2)`, u.Email, u.ID)(
+			Update([]tab.Column{u.Email}, u.ID)),
+		q("update returning", `UPDATE "app"."users" SET "email" = $1 WHERE ("id" = $2) RETURNING "id","email"`, u.Email, u.ID)(
+			UpdateR([]tab.Column{u.Email}, []tab.Column{u.ID, u.Email}, u.ID)),
+		q("delete", `DELETE FROM "app"."users" WHERE ("id" = $1)`, u.ID)(
+			Delete(u, u.ID)),
+		q("delete returning", `DELETE FROM "app"."users" WHERE ("id" = $1) RETURNING "email"`, u.ID)(
+			DeleteR(u, []tab.Column{u.Email}, u.ID)),
+	})
+}
+
+func TestDialects(t *testing.T) {
+	u := users{ID: 1, Email: "a@b.c"}
+	my := New(dialect.MySQL)
+	lite := New(dialect.SQLite)
+	checkQueries(t, []query{
+		q("mysql select", "SELECT `id` FROM `app`.`users` WHERE (`id` = ?)", u.ID)(
+			my.Select([]tab.Column{u.ID}, u.ID)),
+		q("sqlite update", `UPDATE "app"."users" SET "email" = ? WHERE ("id" = ?)`, u.Email, u.ID)(
+			lite.Update([]tab.Column{u.Email}, u.ID)),
+		q("question select", `SELECT "id" FROM "app"."users" WHERE ("id" = ?)`, u.ID)(
+			Q.Select([]tab.Column{u.ID}, u.ID)),
+	})
+	if _, err := my.InsertR([]tab.Column{u.Email}, u.ID); err == nil {
+		t.Error("mysql generated a RETURNING clause")
+	}
+}
+
+func TestStatementErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"select", second(Select(nil))},
+		{"insert", second(Insert(nil))},
+		{"update", second(Update(nil, userID(1)))},
+	}
+	for _, c := range cases {
+		if _, ok := c.err.(tab.QueryGenerationError); !ok {
+			t.Errorf("%s without columns: got %v, want a QueryGenerationError", c.name, c.err)
+		}
+	}
+}
+
+func second(_ tab.Querier, err error) error {
+	return err
+}
+
+func TestUnconditional(t *testing.T) {
+	u := users{ID: 1, Email: "a@b.c"}
+	cases := []struct {
+		name string
+		err  error
+		stmt string
+	}{
+		{"update", second(Update([]tab.Column{u.Email})), "UPDATE"},
+		{"update returning", second(UpdateR([]tab.Column{u.Email}, []tab.Column{u.ID})), "UPDATE"},
+		{"update where", second(UpdateWhere([]tab.Column{u.Email}, nil)), "UPDATE"},
+		{"delete", second(Delete(u)), "DELETE"},
+		{"delete returning", second(DeleteR(u, []tab.Column{u.ID})), "DELETE"},
+		{"delete where", second(DeleteWhere(u, nil)), "DELETE"},
+		{"update empty where", second(UpdateWhere([]tab.Column{u.Email}, pred.And())), "UPDATE"},
+		{"delete empty where", second(DeleteWhere(u, pred.And(pred.Or(), pred.Not(pred.Or())))), "DELETE"},
+		{"soft delete", second(Delete(posts{})), "DELETE"},
+	}
+	for _, c := range cases {
+		if err, ok := c.err.(tab.UnconditionalError); !ok || err.Statement != c.stmt {
+			t.Errorf("%s: got %v, want an UnconditionalError", c.name, c.err)
+		}
+	}
+	checkQueries(t, []query{
+		q("all rows update", `UPDATE "app"."users" SET "email" = $1`, u.Email)(
+			AllRows().Update([]tab.Column{u.Email})),
+		q("all rows delete", `DELETE FROM "app"."users"`)(
+			AllRows().Delete(u)),
+	})
+}
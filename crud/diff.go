@@ -0,0 +1,111 @@
+package crud
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"time"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// ErrNoChanges is returned by UpdateDiff when before and after hold the same values
+var ErrNoChanges = tab.QueryGenerationError{Message: "No columns changed"}
+
+// Change holds the driver values of a column that differ between two rows
+type Change struct {
+	Before driver.Value `json:"before"`
+	After  driver.Value `json:"after"`
+}
+
+// UpdateDiff generates query to update only the columns of after that differ from before, given conditions
+// The version of before is checked for a tabua.Versioned table, ErrNoChanges is returned when nothing changed
+func (b Builder) UpdateDiff(before, after tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	cols, err := Diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := before.(tab.Versioned); ok {
+		// the update bumps the version, it only matches rows still at the version of before
+		name := v.VersionColumn().Name()
+		ver, ok := columnNamed(before.Columns(), name)
+		if !ok {
+			return nil, tab.QueryGenerationError{Message: "row of " + before.Name() + " has no version column " + name}
+		}
+		changed := make([]tab.Column, 0, len(cols))
+		for _, c := range cols {
+			if c.Name() != name {
+				changed = append(changed, c)
+			}
+		}
+		if len(changed) < 1 {
+			return nil, ErrNoChanges
+		}
+		cols = append(changed, ver)
+	}
+	if len(cols) < 1 {
+		return nil, ErrNoChanges
+	}
+	return b.Update(cols, conditions...)
+}
+
+// UpdateDiff generates query to update only the columns of after that differ from before, given conditions
+func UpdateDiff(before, after tab.Table, conditions ...tab.Column) (q tab.Querier, err error) {
+	return std.UpdateDiff(before, after, conditions...)
+}
+
+// Diff returns the columns of after whose driver values differ from the same columns of before
+func Diff(before, after tab.Table) ([]tab.Column, error) {
+	cols := []tab.Column{}
+	err := diff(before, after, func(c tab.Column, _, _ driver.Value) {
+		cols = append(cols, c)
+	})
+	return cols, err
+}
+
+// Changes returns the values of the columns that differ between before and after by column name, for audit logs
+func Changes(before, after tab.Table) (map[string]Change, error) {
+	changes := map[string]Change{}
+	err := diff(before, after, func(c tab.Column, b, a driver.Value) {
+		changes[c.Name()] = Change{Before: b, After: a}
+	})
+	return changes, err
+}
+
+// diff calls changed for each column of after whose value differs from before
+func diff(before, after tab.Table, changed func(c tab.Column, b, a driver.Value)) error {
+	if !sameTable(before, after) {
+		return tab.QueryGenerationError{Message: "can't compare rows of " + before.Name() + " and " + after.Name()}
+	}
+	prev := before.Columns()
+	for _, c := range after.Columns() {
+		p, ok := columnNamed(prev, c.Name())
+		if !ok {
+			return tab.QueryGenerationError{Message: "row of " + before.Name() + " has no column " + c.Name()}
+		}
+		b, err := tab.ValueOf(p)
+		if err != nil {
+			return err
+		}
+		a, err := tab.ValueOf(c)
+		if err != nil {
+			return err
+		}
+		if !sameValue(b, a) {
+			changed(c, b, a)
+		}
+	}
+	return nil
+}
+
+// sameValue compares driver values
+func sameValue(a, b driver.Value) bool {
+	switch a := a.(type) {
+	case []byte:
+		bb, ok := b.([]byte)
+		return ok && (a == nil) == (bb == nil) && bytes.Equal(a, bb)
+	case time.Time:
+		bt, ok := b.(time.Time)
+		return ok && a.Equal(bt)
+	}
+	return a == b
+}
@@ -0,0 +1,88 @@
+package crud
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// drafts is versioned but leaves its version out of its columns
+type drafts struct{ ID docID }
+
+func (drafts) Name() string                  { return "docs" }
+func (drafts) Constraints() []tab.Constraint { return nil }
+func (d drafts) Columns() []tab.Column       { return []tab.Column{d.ID} }
+func (drafts) VersionColumn() tab.Column     { return docVersion(0) }
+
+func TestUpdateDiff(t *testing.T) {
+	before := users{ID: 1, Email: "a", Nick: nick("n")}
+	after := users{ID: 1, Email: "b", Nick: nick("n")}
+	doc := docs{ID: 1, Body: "a", Version: 3}
+	checkQueries(t, []query{
+		q("changed", `UPDATE "app"."users" SET "email" = $1 WHERE ("id" = $2)`, after.Email, after.ID)(
+			UpdateDiff(before, after, after.ID)),
+		q("nulled", `UPDATE "app"."users" SET "nick" = $1 WHERE ("id" = $2)`, userNick{}, after.ID)(
+			UpdateDiff(before, users{ID: 1, Email: "a"}, after.ID)),
+		q("version of before", `UPDATE "docs" SET "body" = $1, "version" = "version" + 1 WHERE (("id" = $2) AND "version" = $3)`, docBody("b"), doc.ID, docVersion(3))(
+			UpdateDiff(doc, docs{ID: 1, Body: "b", Version: 9}, doc.ID)),
+		q("version unchanged", `UPDATE "docs" SET "body" = $1, "version" = "version" + 1 WHERE (("id" = $2) AND "version" = $3)`, docBody("b"), doc.ID, docVersion(3))(
+			UpdateDiff(doc, docs{ID: 1, Body: "b", Version: 3}, doc.ID)),
+	})
+
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"no changes", second(UpdateDiff(before, before, before.ID)), ErrNoChanges},
+		{"only the version", second(UpdateDiff(doc, docs{ID: 1, Body: "a", Version: 4}, doc.ID)), ErrNoChanges},
+	}
+	for _, c := range cases {
+		if c.err != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, c.err, c.want)
+		}
+	}
+	if _, err := UpdateDiff(drafts{1}, drafts{2}, docID(1)); err == nil {
+		t.Error("diffed a row without its version")
+	}
+	if _, err := UpdateDiff(before, doc, before.ID); err == nil {
+		t.Error("diffed rows of different tables")
+	}
+}
+
+func TestChanges(t *testing.T) {
+	got, err := Changes(users{ID: 1, Email: "a"}, users{ID: 1, Email: "b", Nick: nick("n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Change{
+		"email": {Before: "a", After: "b"},
+		"nick":  {Before: nil, After: "n"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSameValue(t *testing.T) {
+	cases := []struct {
+		a, b driver.Value
+		want bool
+	}{
+		{int64(1), int64(1), true},
+		{int64(1), 1.0, false},
+		{[]byte("a"), []byte("a"), true},
+		{[]byte{}, []byte(nil), false},
+		{[]byte("a"), "a", false},
+		{nil, nil, true},
+		{time.Unix(0, 0).UTC(), time.Unix(0, 0).In(time.FixedZone("BRT", -3*3600)), true},
+	}
+	for _, c := range cases {
+		if got := sameValue(c.a, c.b); got != c.want {
+			t.Errorf("%#v %#v: got %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+type timeoutKey struct{}
+
+// WithTimeout bounds every statement run with ctx to d, overriding the Builder's timeout.
+// The driver cancels statements still running on the server when it runs out
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// Timeout returns a copy of the Builder bounding every statement it runs to d
+func (b Builder) Timeout(d time.Duration) Builder {
+	b.timeout = d
+	return b
+}
+
+// Hook returns a copy of the Builder running hooks around every statement it executes, after its current ones
+// The Generate of a tabua.GenerateHook also runs on every query the Builder generates
+func (b Builder) Hook(hooks ...tab.Hook) Builder {
+	b.hooks = append(append(tab.Hooks(nil), b.hooks...), hooks...)
+	return b
+}
+
+// chain returns the shared hooks of tabua.UseHooks followed by the Builder's
+func (b Builder) chain() tab.Hooks {
+	return tab.SharedHooks(b.hooks...)
+}
+
+// Exec runs q, generated by the Builder
+// Constraint violations are returned as the typed errors of tabua.Classify
+func (b Builder) Exec(ctx context.Context, ex sqlx.ExtContext, q tab.Querier) (sql.Result, error) {
+	ctx, cancel := b.context(ctx)
+	defer cancel()
+	hooks := b.chain()
+	hq, err := hooks.Before(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	res, err := b.exec(ctx, ex, b.bind(ex, hq), hq.Args())
+	err = tab.Classify(err)
+	rows := int64(-1)
+	if err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			rows = n
+		}
+		err = staleResult(q, res)
+	}
+	hooks.After(ctx, tab.QueryEvent{Query: hq, Duration: time.Since(start), Rows: rows, Err: err})
+	return res, err
+}
+
+// Row runs q and scans its single row into dest
+func (b Builder) Row(ctx context.Context, ex sqlx.ExtContext, dest interface{}, q tab.Querier) error {
+	ctx, cancel := b.context(ctx)
+	defer cancel()
+	hooks := b.chain()
+	hq, err := hooks.Before(ctx, q)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = staleRow(q, tab.Classify(b.get(ctx, ex, dest, b.bind(ex, hq), hq.Args())))
+	rows := int64(0)
+	if err == nil {
+		rows = 1
+	}
+	hooks.After(ctx, tab.QueryEvent{Query: hq, Duration: time.Since(start), Rows: rows, Err: err})
+	return err
+}
+
+// Rows runs q and scans all rows into dest, a pointer to a slice
+func (b Builder) Rows(ctx context.Context, ex sqlx.ExtContext, dest interface{}, q tab.Querier) error {
+	ctx, cancel := b.context(ctx)
+	defer cancel()
+	hooks := b.chain()
+	hq, err := hooks.Before(ctx, q)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	before := sliceLen(dest)
+	err = tab.Classify(b.selectRows(ctx, ex, dest, b.bind(ex, hq), hq.Args()))
+	if err == nil {
+		err = staleRows(q, dest, before)
+	}
+	rows := int64(sliceLen(dest) - before)
+	hooks.After(ctx, tab.QueryEvent{Query: hq, Duration: time.Since(start), Rows: rows, Err: err})
+	return err
+}
+
+// Get selects cols into dest for the single row matching conditions
+// dest is usually a generated table struct, sql.ErrNoRows is returned when nothing matches
+func (b Builder) Get(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, conditions ...tab.Column) error {
+	q, err := b.Select(cols, conditions...)
+	if err != nil {
+		return err
+	}
+	return b.Row(ctx, ex, dest, q)
+}
+
+// GetWhere selects cols into dest for the single row matched by where
+func (b Builder) GetWhere(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, where pred.Predicate) error {
+	q, err := b.SelectWhere(cols, where)
+	if err != nil {
+		return err
+	}
+	return b.Row(ctx, ex, dest, q)
+}
+
+// List selects cols into dest, a pointer to a slice, for all rows matching conditions
+func (b Builder) List(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, conditions ...tab.Column) error {
+	q, err := b.Select(cols, conditions...)
+	if err != nil {
+		return err
+	}
+	return b.Rows(ctx, ex, dest, q)
+}
+
+// ListWhere selects cols into dest, a pointer to a slice, for all rows matched by where
+func (b Builder) ListWhere(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, where pred.Predicate) error {
+	q, err := b.SelectWhere(cols, where)
+	if err != nil {
+		return err
+	}
+	return b.Rows(ctx, ex, dest, q)
+}
+
+// ListPage selects cols into dest, a pointer to a slice, for the page p of rows matched by where
+func (b Builder) ListPage(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, where pred.Predicate, p Page) error {
+	q, err := b.SelectPage(cols, where, p)
+	if err != nil {
+		return err
+	}
+	return b.Rows(ctx, ex, dest, q)
+}
+
+// InsertReturning inserts cols and scans the returning columns into dest
+func (b Builder) InsertReturning(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, returning ...tab.Column) error {
+	q, err := b.InsertR(cols, returning...)
+	if err != nil {
+		return err
+	}
+	return b.Row(ctx, ex, dest, q)
+}
+
+// UpdateReturning updates the rows matching conditions and scans the returning columns into dest, a pointer to a slice
+func (b Builder) UpdateReturning(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, returning []tab.Column, conditions ...tab.Column) error {
+	q, err := b.UpdateR(cols, returning, conditions...)
+	if err != nil {
+		return err
+	}
+	return b.Rows(ctx, ex, dest, q)
+}
+
+// Exec runs q, generated by the default builder
+func Exec(ctx context.Context, ex sqlx.ExtContext, q tab.Querier) (sql.Result, error) {
+	return std.Exec(ctx, ex, q)
+}
+
+// Row runs q, generated by the default builder, and scans its single row into dest
+func Row(ctx context.Context, ex sqlx.ExtContext, dest interface{}, q tab.Querier) error {
+	return std.Row(ctx, ex, dest, q)
+}
+
+// Rows runs q, generated by the default builder, and scans all rows into dest, a pointer to a slice
+func Rows(ctx context.Context, ex sqlx.ExtContext, dest interface{}, q tab.Querier) error {
+	return std.Rows(ctx, ex, dest, q)
+}
+
+// Get selects cols into dest for the single row matching conditions
+// dest is usually a generated table struct, sql.ErrNoRows is returned when nothing matches
+func Get(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, conditions ...tab.Column) error {
+	return std.Get(ctx, ex, dest, cols, conditions...)
+}
+
+// GetWhere selects cols into dest for the single row matched by where
+func GetWhere(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, where pred.Predicate) error {
+	return std.GetWhere(ctx, ex, dest, cols, where)
+}
+
+// List selects cols into dest, a pointer to a slice, for all rows matching conditions
+func List(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, conditions ...tab.Column) error {
+	return std.List(ctx, ex, dest, cols, conditions...)
+}
+
+// ListWhere selects cols into dest, a pointer to a slice, for all rows matched by where
+func ListWhere(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, where pred.Predicate) error {
+	return std.ListWhere(ctx, ex, dest, cols, where)
+}
+
+// ListPage selects cols into dest, a pointer to a slice, for the page p of rows matched by where
+func ListPage(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, where pred.Predicate, p Page) error {
+	return std.ListPage(ctx, ex, dest, cols, where, p)
+}
+
+// InsertReturning inserts cols and scans the returning columns into dest
+func InsertReturning(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, returning ...tab.Column) error {
+	return std.InsertReturning(ctx, ex, dest, cols, returning...)
+}
+
+// UpdateReturning updates the rows matching conditions and scans the returning columns into dest, a pointer to a slice
+func UpdateReturning(ctx context.Context, ex sqlx.ExtContext, dest interface{}, cols []tab.Column, returning []tab.Column, conditions ...tab.Column) error {
+	return std.UpdateReturning(ctx, ex, dest, cols, returning, conditions...)
+}
+
+// context applies the statement timeout of ctx, or else the Builder's
+func (b Builder) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	d := b.timeout
+	if t, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		d = t
+	}
+	if d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return ctx, func() {}
+}
+
+// exec runs query on ex, through the Builder's StmtCache when ex is a pool
+func (b Builder) exec(ctx context.Context, ex sqlx.ExtContext, query string, args []interface{}) (res sql.Result, err error) {
+	if db, ok := ex.(*sqlx.DB); ok && b.cache != nil {
+		err = b.cache.with(ctx, db, query, func(s *sqlx.Stmt) error {
+			res, err = s.ExecContext(ctx, args...)
+			return err
+		})
+		return res, err
+	}
+	return ex.ExecContext(ctx, query, args...)
+}
+
+// get scans the single row of query into dest, through the Builder's StmtCache when ex is a pool
+func (b Builder) get(ctx context.Context, ex sqlx.ExtContext, dest interface{}, query string, args []interface{}) error {
+	if db, ok := ex.(*sqlx.DB); ok && b.cache != nil {
+		return b.cache.with(ctx, db, query, func(s *sqlx.Stmt) error {
+			return s.GetContext(ctx, dest, args...)
+		})
+	}
+	return sqlx.GetContext(ctx, ex, dest, query, args...)
+}
+
+// selectRows scans the rows of query into dest, through the Builder's StmtCache when ex is a pool
+func (b Builder) selectRows(ctx context.Context, ex sqlx.ExtContext, dest interface{}, query string, args []interface{}) error {
+	if db, ok := ex.(*sqlx.DB); ok && b.cache != nil {
+		return b.cache.with(ctx, db, query, func(s *sqlx.Stmt) error {
+			return s.SelectContext(ctx, dest, args...)
+		})
+	}
+	return sqlx.SelectContext(ctx, ex, dest, query, args...)
+}
+
+// bind rewrites ? placeholders to the bindvars of ex's driver
+func (b Builder) bind(ex sqlx.ExtContext, q tab.Querier) string {
+	if b.d.Placeholder(1) == "?" {
+		return ex.Rebind(q.SQL())
+	}
+	return q.SQL()
+}
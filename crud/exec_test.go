@@ -0,0 +1,359 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// fakeDB is a driver recording the statements run on it and answering every query with its rows
+type fakeDB struct {
+	mu sync.Mutex
+	// log holds the statements run, and begin, commit and rollback
+	log      []string
+	args     [][]driver.Value
+	prepared int
+	closed   int
+	cols     []string
+	rows     [][]driver.Value
+	affected int64
+	// fail returns the error of a statement, nil by default
+	fail func(query string) error
+	// deadline is whether the last statement ran with a deadline
+	deadline bool
+}
+
+func open(f *fakeDB) *sqlx.DB {
+	return sqlx.NewDb(sql.OpenDB(f), "postgres")
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeDB) record(query string, args []driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, query)
+	f.args = append(f.args, args)
+}
+
+func (f *fakeDB) statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.log...)
+}
+
+type fakeConn struct{ f *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.prepared++
+	return &fakeStmt{c.f, query}, nil
+}
+func (c fakeConn) Close() error { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+func (c fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.f.record("BEGIN", nil)
+	return c, nil
+}
+func (c fakeConn) Commit() error {
+	c.f.record("COMMIT", nil)
+	if c.f.fail != nil {
+		return c.f.fail("COMMIT")
+	}
+	return nil
+}
+func (c fakeConn) Rollback() error {
+	c.f.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeStmt struct {
+	f     *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	s.f.mu.Lock()
+	defer s.f.mu.Unlock()
+	s.f.closed++
+	return nil
+}
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec without a context")
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("query without a context")
+}
+
+func (s *fakeStmt) run(ctx context.Context, args []driver.NamedValue) error {
+	vs := []driver.Value{}
+	for _, a := range args {
+		vs = append(vs, a.Value)
+	}
+	s.f.record(s.query, vs)
+	s.f.mu.Lock()
+	_, s.f.deadline = ctx.Deadline()
+	s.f.mu.Unlock()
+	if s.f.fail != nil {
+		return s.f.fail(s.query)
+	}
+	return nil
+}
+
+func (s *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.run(ctx, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(s.f.affected), nil
+}
+
+func (s *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.run(ctx, args); err != nil {
+		return nil, err
+	}
+	return &fakeRows{cols: s.f.cols, rows: s.f.rows}, nil
+}
+
+type fakeRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type userRow struct {
+	ID    userID    `db:"id"`
+	Email userEmail `db:"email"`
+}
+
+func TestExec(t *testing.T) {
+	f := &fakeDB{affected: 2}
+	u := users{ID: 1, Email: "a@b.c"}
+	q, err := Update([]tab.Column{u.Email}, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Exec(context.Background(), open(f), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("got %d rows affected, want 2", n)
+	}
+	want := []string{`UPDATE "app"."users" SET "email" = $1 WHERE ("id" = $2)`}
+	if got := f.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got statements %q, want %q", got, want)
+	}
+	if want := []driver.Value{"a@b.c", int64(1)}; !reflect.DeepEqual(f.args[0], want) {
+		t.Errorf("got args %v, want %v", f.args[0], want)
+	}
+}
+
+func TestExecRebind(t *testing.T) {
+	f := &fakeDB{}
+	u := users{ID: 1}
+	q, _ := Q.Delete(u, u.ID)
+	if _, err := Q.Exec(context.Background(), open(f), q); err != nil {
+		t.Fatal(err)
+	}
+	if want := `DELETE FROM "app"."users" WHERE ("id" = $1)`; f.statements()[0] != want {
+		t.Errorf("got %q, want %q rebound to the driver", f.statements()[0], want)
+	}
+}
+
+func TestGet(t *testing.T) {
+	f := &fakeDB{cols: []string{"id", "email"}, rows: [][]driver.Value{{int64(1), "a@b.c"}}}
+	var got userRow
+	err := Get(context.Background(), open(f), &got, []tab.Column{userID(0), userEmail("")}, userID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (userRow{1, "a@b.c"}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if want := `SELECT "id", "email" FROM "app"."users" WHERE ("id" = $1)`; f.statements()[0] != want {
+		t.Errorf("got %q, want %q", f.statements()[0], want)
+	}
+
+	f = &fakeDB{cols: []string{"id", "email"}}
+	err = Get(context.Background(), open(f), &got, []tab.Column{userID(0), userEmail("")}, userID(1))
+	if err != sql.ErrNoRows {
+		t.Errorf("got %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	f := &fakeDB{cols: []string{"id", "email"}, rows: [][]driver.Value{{int64(1), "a@b.c"}, {int64(2), "d@e.f"}}}
+	var got []userRow
+	if err := List(context.Background(), open(f), &got, []tab.Column{userID(0), userEmail("")}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []userRow{{1, "a@b.c"}, {2, "d@e.f"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if want := `SELECT "id", "email" FROM "app"."users"`; f.statements()[0] != want {
+		t.Errorf("got %q, want %q", f.statements()[0], want)
+	}
+}
+
+func TestExecErrors(t *testing.T) {
+	f := &fakeDB{fail: func(string) error {
+		return &pq.Error{Code: "23505", Table: "users", Constraint: "users_email_key", Message: "duplicate"}
+	}}
+	var got userRow
+	err := InsertReturning(context.Background(), open(f), &got, []tab.Column{userEmail("a@b.c")}, userID(0))
+	if _, ok := err.(tab.UniqueViolation); !ok {
+		t.Errorf("got %T %v, want a UniqueViolation", err, err)
+	}
+	if err := Get(context.Background(), open(f), &got, nil); err == nil {
+		t.Error("selected no columns")
+	} else if len(f.statements()) != 1 {
+		t.Errorf("ran %q for an invalid query", f.statements()[1:])
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	q, _ := Delete(users{}, userID(1))
+	cases := []struct {
+		name string
+		b    Builder
+		ctx  context.Context
+		want bool
+	}{
+		{"none", std, context.Background(), false},
+		{"builder", std.Timeout(time.Minute), context.Background(), true},
+		{"context", std, WithTimeout(context.Background(), time.Minute), true},
+		{"context disables", std.Timeout(time.Minute), WithTimeout(context.Background(), 0), false},
+	}
+	for _, c := range cases {
+		f := &fakeDB{}
+		if _, err := c.b.Exec(c.ctx, open(f), q); err != nil {
+			t.Fatal(err)
+		}
+		if f.deadline != c.want {
+			t.Errorf("%s: got deadline %v, want %v", c.name, f.deadline, c.want)
+		}
+	}
+}
+
+func TestExecHooks(t *testing.T) {
+	q, _ := Delete(users{}, userID(1))
+	events := []tab.QueryEvent{}
+	rewrite := tab.HookFuncs{
+		BeforeFunc: func(_ context.Context, q tab.Querier) (tab.Querier, error) {
+			return tab.Sq{S: q.SQL() + " /*x*/", A: q.Args()}, nil
+		},
+		AfterFunc: func(_ context.Context, e tab.QueryEvent) { events = append(events, e) },
+	}
+	f := &fakeDB{affected: 3}
+	if _, err := std.Hook(rewrite).Exec(context.Background(), open(f), q); err != nil {
+		t.Fatal(err)
+	}
+	if want := q.SQL() + " /*x*/"; f.statements()[0] != want {
+		t.Errorf("ran %q, want %q", f.statements()[0], want)
+	}
+	if len(events) != 1 || events[0].Rows != 3 || events[0].Err != nil || events[0].Query.SQL() != f.statements()[0] {
+		t.Errorf("got events %+v", events)
+	}
+
+	none := tab.HookFuncs{BeforeFunc: func(context.Context, tab.Querier) (tab.Querier, error) { return nil, nil }}
+	f = &fakeDB{}
+	var got []userRow
+	if err := std.Hook(none).Rows(context.Background(), open(f), &got, q); err != tab.ErrNoQuery {
+		t.Errorf("got %v, want ErrNoQuery", err)
+	}
+	if len(f.statements()) > 0 {
+		t.Errorf("ran %q for a blocked query", f.statements())
+	}
+
+	events = events[:0]
+	f = &fakeDB{fail: func(string) error { return sql.ErrConnDone }}
+	if err := std.Hook(rewrite).Row(context.Background(), open(f), &userRow{}, q); err != sql.ErrConnDone {
+		t.Errorf("got %v, want sql.ErrConnDone", err)
+	}
+	if len(events) != 1 || events[0].Err != sql.ErrConnDone || events[0].Rows != 0 {
+		t.Errorf("got events %+v for a failed query", events)
+	}
+}
+
+func TestGenerateHooks(t *testing.T) {
+	tag := tab.HookFuncs{GenerateFunc: func(q tab.Querier) (tab.Querier, error) {
+		return tab.Sq{S: q.SQL() + " /*x*/", A: q.Args()}, nil
+	}}
+	b := std.Hook(tag)
+	u := users{ID: 1, Email: "a@b.c"}
+	checkQueries(t, []query{
+		q("select", `SELECT "id" FROM "app"."users" WHERE ("id" = $1) /*x*/`, u.ID)(
+			b.Select([]tab.Column{u.ID}, u.ID)),
+		q("delete", `DELETE FROM "app"."users" WHERE ("id" = $1) /*x*/`, u.ID)(
+			b.Delete(u, u.ID)),
+		q("exists", `SELECT EXISTS(SELECT 1 FROM "app"."users" WHERE ("id" = $1)) /*x*/`, u.ID)(
+			b.Exists(u, u.ID)),
+	})
+
+	// a rewritten versioned update is still checked
+	d := docs{ID: 1, Body: "b", Version: 3}
+	qr, err := b.Update([]tab.Column{d.Body}, d.ID, d.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Exec(context.Background(), open(&fakeDB{}), qr); err == nil {
+		t.Errorf("got no ErrStaleRow for %s", qr.SQL())
+	}
+
+	blocked := errors.New("blocked")
+	block := tab.HookFuncs{GenerateFunc: func(tab.Querier) (tab.Querier, error) { return nil, blocked }}
+	if _, err := std.Hook(block).Insert([]tab.Column{u.Email}); err != blocked {
+		t.Errorf("got %v, want the hook's error", err)
+	}
+}
+
+func TestSharedHooks(t *testing.T) {
+	defer tab.ResetHooks()
+	calls := []string{}
+	record := func(name string) func(context.Context, tab.Querier) (tab.Querier, error) {
+		return func(_ context.Context, q tab.Querier) (tab.Querier, error) {
+			calls = append(calls, name)
+			return q, nil
+		}
+	}
+	tab.UseHooks(tab.HookFuncs{
+		GenerateFunc: func(q tab.Querier) (tab.Querier, error) { return record("generate")(context.Background(), q) },
+		BeforeFunc:   record("before"),
+	})
+	own := tab.HookFuncs{BeforeFunc: record("own")}
+	q, err := Delete(users{}, userID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := std.Hook(own).Exec(context.Background(), open(&fakeDB{}), q); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"generate", "before", "own"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,146 @@
+package crud
+
+import (
+	"strings"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// Named generates query from sql with :column parameters, named after the columns of t,
+// bound from arg, a tabua.Table row or a map[string]interface{} keyed by column name
+// Text in quotes, comments, dollar quoted bodies and :: casts are left as is
+func (b Builder) Named(t tab.Table, sql string, arg interface{}) (q tab.Querier, err error) {
+	lookup, err := namedArgs(t, arg)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, c := range t.Columns() {
+		names[c.Name()] = true
+	}
+
+	buf := &strings.Builder{}
+	args := []interface{}{}
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := strings.IndexByte(sql[i+1:], ch)
+			if end < 0 {
+				return nil, tab.QueryGenerationError{Message: "unterminated quote in named query"}
+			}
+			buf.WriteString(sql[i : i+end+2])
+			i += end + 1
+		case ch == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			buf.WriteString(sql[i : i+end])
+			i += end - 1
+		case ch == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := blockComment(sql[i:])
+			if end < 0 {
+				return nil, tab.QueryGenerationError{Message: "unterminated comment in named query"}
+			}
+			buf.WriteString(sql[i : i+end])
+			i += end - 1
+		case ch == '$' && dollarTag(sql[i:]) != "":
+			tag := dollarTag(sql[i:])
+			end := strings.Index(sql[i+len(tag):], tag)
+			if end < 0 {
+				return nil, tab.QueryGenerationError{Message: "unterminated " + tag + " quote in named query"}
+			}
+			end += 2 * len(tag)
+			buf.WriteString(sql[i : i+end])
+			i += end - 1
+		case ch == ':' && i+1 < len(sql) && sql[i+1] == ':':
+			buf.WriteString("::")
+			i++
+		case ch == ':' && i+1 < len(sql) && isNameStart(sql[i+1]):
+			end := i + 1
+			for end < len(sql) && isNamePart(sql[end]) {
+				end++
+			}
+			name := sql[i+1 : end]
+			if !names[name] {
+				return nil, tab.QueryGenerationError{Message: "unknown column :" + name + " of " + t.Name()}
+			}
+			v, ok := lookup(name)
+			if !ok {
+				return nil, tab.QueryGenerationError{Message: "no value for :" + name}
+			}
+			args = append(args, v)
+			buf.WriteString(b.d.Placeholder(len(args)))
+			i = end - 1
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+	return b.query(t, buf.String(), args)
+}
+
+// Named generates query from sql with :column parameters for the default builder
+func Named(t tab.Table, sql string, arg interface{}) (q tab.Querier, err error) {
+	return std.Named(t, sql, arg)
+}
+
+// namedArgs returns the lookup of the values in arg by column name
+func namedArgs(t tab.Table, arg interface{}) (func(name string) (interface{}, bool), error) {
+	switch a := arg.(type) {
+	case tab.Table:
+		if !sameTable(a, t) {
+			return nil, tab.QueryGenerationError{Message: "named query of " + t.Name() + " bound from a row of " + a.Name()}
+		}
+		cols := a.Columns()
+		return func(name string) (interface{}, bool) {
+			return columnNamed(cols, name)
+		}, nil
+	case map[string]interface{}:
+		return func(name string) (interface{}, bool) {
+			v, ok := a[name]
+			return v, ok
+		}, nil
+	}
+	return nil, tab.QueryGenerationError{Message: "named query arguments must be a table row or a map"}
+}
+
+// blockComment returns the length of the /* */ comment sql starts with, nested ones included,
+// -1 when it isn't closed
+func blockComment(sql string) int {
+	depth := 0
+	for i := 0; i+1 < len(sql); i++ {
+		switch sql[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// dollarTag returns the $tag$ delimiter sql starts with, empty when it's a $1 placeholder or a lone $
+func dollarTag(sql string) string {
+	end := 1
+	for end < len(sql) && (isNamePart(sql[end]) && (end > 1 || isNameStart(sql[end]))) {
+		end++
+	}
+	if end < len(sql) && sql[end] == '$' {
+		return sql[:end+1]
+	}
+	return ""
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,65 @@
+package crud
+
+import (
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+)
+
+func TestNamed(t *testing.T) {
+	u := users{ID: 1, Email: "a@b.c"}
+	args := map[string]interface{}{"id": 2, "email": "x"}
+	checkQueries(t, []query{
+		q("row", `SELECT * FROM "app"."users" WHERE "id" = $1 AND "email" = $2`, u.ID, u.Email)(
+			Named(u, `SELECT * FROM "app"."users" WHERE "id" = :id AND "email" = :email`, u)),
+		q("map", `UPDATE users SET email = $1 WHERE id = $2`, "x", 2)(
+			Named(u, `UPDATE users SET email = :email WHERE id = :id`, args)),
+		q("repeated", `SELECT $1, $2`, 2, 2)(
+			Named(u, `SELECT :id, :id`, args)),
+		q("cast", `SELECT $1::text`, "x")(
+			Named(u, `SELECT :email::text`, args)),
+		q("quotes", `SELECT ':id', ":email", $1`, 2)(
+			Named(u, `SELECT ':id', ":email", :id`, args)),
+		q("line comment", "SELECT $1 -- by :email\nFROM users", 2)(
+			Named(u, "SELECT :id -- by :email\nFROM users", args)),
+		q("trailing line comment", `SELECT $1 -- :email`, 2)(
+			Named(u, `SELECT :id -- :email`, args)),
+		q("block comment", `SELECT /* :email */ $1`, 2)(
+			Named(u, `SELECT /* :email */ :id`, args)),
+		q("nested block comment", `SELECT /* a /* :email */ :nope */ $1`, 2)(
+			Named(u, `SELECT /* a /* :email */ :nope */ :id`, args)),
+		q("dollar quotes", `SELECT $$:email$$, $1`, 2)(
+			Named(u, `SELECT $$:email$$, :id`, args)),
+		q("tagged dollar quotes", `DO $body$ SELECT ':x' $$ :email $body$; SELECT $1`, 2)(
+			Named(u, `DO $body$ SELECT ':x' $$ :email $body$; SELECT :id`, args)),
+		q("placeholder", `SELECT $1, $1`, 2)(
+			Named(u, `SELECT $1, :id`, args)),
+		q("minus", `SELECT $1 - 1 / 2`, 2)(
+			Named(u, `SELECT :id - 1 / 2`, args)),
+		q("mysql", "SELECT `:id` FROM users WHERE id = ?", 2)(
+			New(dialect.MySQL).Named(u, "SELECT `:id` FROM users WHERE id = :id", args)),
+	})
+
+	cases := []struct {
+		name string
+		sql  string
+		arg  interface{}
+	}{
+		{"unknown column", `SELECT :name`, args},
+		{"no value", `SELECT :nick`, args},
+		{"unterminated quote", `SELECT ':id`, args},
+		{"unterminated comment", `SELECT /* :id`, args},
+		{"unterminated nested comment", `SELECT /* /* */ :id`, args},
+		{"unterminated dollar quote", `SELECT $a$ :id $b$`, args},
+		{"row of another table", `SELECT :id`, posts{ID: 1}},
+		{"arguments", `SELECT :id`, []interface{}{1}},
+	}
+	for _, c := range cases {
+		if _, err := Named(u, c.sql, c.arg); err == nil {
+			t.Errorf("%s: generated %s", c.name, c.sql)
+		} else if _, ok := err.(tab.QueryGenerationError); !ok {
+			t.Errorf("%s: got %v, want a QueryGenerationError", c.name, err)
+		}
+	}
+}
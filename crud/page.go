@@ -0,0 +1,271 @@
+package crud
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// ErrInvalidCursor is returned for a Page.After token that doesn't match the page's order, its columns and directions
+var ErrInvalidCursor = tab.QueryGenerationError{Message: "invalid page cursor"}
+
+// Order is an ORDER BY term
+type Order struct {
+	Column tab.Column
+	Desc   bool
+	Nulls  tab.Nulls
+}
+
+// Asc orders by c in ascending order
+func Asc(c tab.Column) Order {
+	return Order{Column: c}
+}
+
+// Desc orders by c in descending order
+func Desc(c tab.Column) Order {
+	return Order{Column: c, Desc: true}
+}
+
+// NullsFirst returns a copy of o sorting NULL values before the others
+func (o Order) NullsFirst() Order {
+	o.Nulls = tab.NullsFirst
+	return o
+}
+
+// NullsLast returns a copy of o sorting NULL values after the others
+func (o Order) NullsLast() Order {
+	o.Nulls = tab.NullsLast
+	return o
+}
+
+// Page orders and limits the selected rows
+type Page struct {
+	Order  []Order
+	Limit  uint64
+	Offset uint64
+	// Keyset orders by the table's primary key after Order, so every row
+	// has a distinct position a Cursor can resume from. A table without a
+	// primary key needs an Order on all columns of one of its unique keys
+	Keyset bool
+	// After is the Cursor of the last row of the previous page, the page
+	// seeks past it instead of counting an Offset. It implies Keyset
+	// Keyset columns must not be NULL
+	After string
+}
+
+// SelectPage generates query to select cols columns filtered by where, ordered and limited by p
+// The table is defined by the first column
+func (b Builder) SelectPage(cols []tab.Column, where pred.Predicate, p Page) (q tab.Querier, err error) {
+	return selectOnly(b, cols, where, p)
+}
+
+// SelectPage generates query to select cols columns filtered by where, ordered and limited by p
+// The table is defined by the first column
+func SelectPage(cols []tab.Column, where pred.Predicate, p Page) (q tab.Querier, err error) {
+	return std.SelectPage(cols, where, p)
+}
+
+// Cursor returns the token of row, the last one of a keyset page, to set as After for the next page
+func Cursor(row tab.Table, p Page) (string, error) {
+	p.Keyset = true
+	order, err := p.order(row)
+	if err != nil {
+		return "", err
+	}
+	cols := row.Columns()
+	c := cursor{}
+	for _, o := range order {
+		col, ok := columnNamed(cols, o.Column.Name())
+		if !ok {
+			return "", tab.QueryGenerationError{Message: "row of " + row.Name() + " has no column " + o.Column.Name()}
+		}
+		v, err := tab.ValueOf(col)
+		if err != nil {
+			return "", err
+		}
+		s, err := encodeValue(v)
+		if err != nil {
+			return "", tab.QueryGenerationError{Message: "keyset column " + col.Name() + ": " + err.Error()}
+		}
+		c.Columns = append(c.Columns, col.Name())
+		c.Desc = append(c.Desc, o.Desc)
+		c.Values = append(c.Values, s)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// order returns the ORDER BY terms of the page, with the primary key of t as tiebreaker for keyset pages
+func (p Page) order(t tab.Table) ([]Order, error) {
+	if !p.Keyset && len(p.After) < 1 {
+		return p.Order, nil
+	}
+	order := append([]Order(nil), p.Order...)
+	pk, ok := tab.PrimaryKey(t)
+	if !ok {
+		if !uniqueOrder(t, order) {
+			return nil, tab.QueryGenerationError{Message: "keyset page of " + t.Name() + " needs a primary key or an order on all columns of a unique key"}
+		}
+		return order, nil
+	}
+	// follow the direction of the last term, an index on it can be scanned backwards
+	desc := len(order) > 0 && order[len(order)-1].Desc
+	for _, k := range pk.Keys() {
+		if _, ok := columnNamed(orderColumns(order), k.Name()); !ok {
+			order = append(order, Order{Column: k, Desc: desc})
+		}
+	}
+	return order, nil
+}
+
+// uniqueOrder reports if order includes every column of a unique key of t, each row then has a distinct position
+func uniqueOrder(t tab.Table, order []Order) bool {
+	ct, ok := t.(tab.Constrained)
+	if !ok {
+		return false
+	}
+	cols := orderColumns(order)
+	for _, c := range ct.Constrainers() {
+		u, ok := c.(tab.UniqueConstrainer)
+		if !ok || len(u.Uniques()) < 1 {
+			continue
+		}
+		covered := true
+		for _, k := range u.Uniques() {
+			if _, ok := columnNamed(cols, k.Name()); !ok {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// cursor is the position of a row in a keyset page, and the order it was read in
+type cursor struct {
+	Columns []string `json:"c"`
+	Desc    []bool   `json:"d"`
+	Values  []string `json:"v"`
+}
+
+// seek returns the predicate matching rows after the position of token in order
+func seek(token string, order []Order) (pred.Predicate, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := cursor{}
+	if err := json.Unmarshal(b, &c); err != nil || len(c.Columns) != len(order) || len(c.Desc) != len(order) || len(c.Values) != len(order) {
+		return nil, ErrInvalidCursor
+	}
+	values := make([]interface{}, 0, len(order))
+	for i, o := range order {
+		// a cursor read in another direction would resume on the wrong side of its row
+		if c.Columns[i] != o.Column.Name() || c.Desc[i] != o.Desc {
+			return nil, ErrInvalidCursor
+		}
+		v, err := decodeValue(c.Values[i])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		values = append(values, v)
+	}
+
+	// (a > ?) OR (a = ? AND b > ?) OR ..., row comparison can't mix directions
+	ors := make([]pred.Predicate, 0, len(order))
+	for i, o := range order {
+		ands := make([]pred.Predicate, 0, i+1)
+		for j, prev := range order[:i] {
+			ands = append(ands, pred.Eq(valued{prev.Column, values[j]}))
+		}
+		if o.Desc {
+			ands = append(ands, pred.Lt(valued{o.Column, values[i]}))
+		} else {
+			ands = append(ands, pred.Gt(valued{o.Column, values[i]}))
+		}
+		ors = append(ors, pred.And(ands...))
+	}
+	return pred.Or(ors...), nil
+}
+
+// valued is a column bound to a value read from a cursor
+type valued struct {
+	tab.Column
+	v interface{}
+}
+
+func (c valued) Value() (driver.Value, error) {
+	return c.v, nil
+}
+
+// encodeValue writes a driver value as a string prefixed by its type
+func encodeValue(v driver.Value) (string, error) {
+	switch v := v.(type) {
+	case int64:
+		return "i" + strconv.FormatInt(v, 10), nil
+	case float64:
+		return "f" + strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		return "b" + strconv.FormatBool(v), nil
+	case []byte:
+		return "x" + base64.RawURLEncoding.EncodeToString(v), nil
+	case string:
+		return "s" + v, nil
+	case time.Time:
+		return "t" + v.Format(time.RFC3339Nano), nil
+	case nil:
+		return "", errors.New("NULL has no keyset position")
+	}
+	return "", errors.New("unsupported driver value")
+}
+
+func decodeValue(s string) (driver.Value, error) {
+	if len(s) < 1 {
+		return nil, ErrInvalidCursor
+	}
+	v := s[1:]
+	switch s[0] {
+	case 'i':
+		return strconv.ParseInt(v, 10, 64)
+	case 'f':
+		return strconv.ParseFloat(v, 64)
+	case 'b':
+		return strconv.ParseBool(v)
+	case 'x':
+		return base64.RawURLEncoding.DecodeString(v)
+	case 's':
+		return v, nil
+	case 't':
+		return time.Parse(time.RFC3339Nano, v)
+	}
+	return nil, ErrInvalidCursor
+}
+
+func orderColumns(order []Order) []tab.Column {
+	cols := make([]tab.Column, 0, len(order))
+	for _, o := range order {
+		cols = append(cols, o.Column)
+	}
+	return cols
+}
+
+func columnNamed(cols []tab.Column, name string) (tab.Column, bool) {
+	for _, c := range cols {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,199 @@
+package crud
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"reflect"
+	"testing"
+	"time"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// logins has no primary key but a unique key on email
+type logins struct {
+	Email loginEmail
+	At    loginAt
+}
+
+func (logins) Name() string                    { return "logins" }
+func (logins) Constraints() []tab.Constraint   { return []tab.Constraint{"logins_email_key"} }
+func (l logins) Columns() []tab.Column         { return []tab.Column{l.Email, l.At} }
+func (logins) Constrainers() []tab.Constrainer { return []tab.Constrainer{loginsEmailKey{}} }
+
+type loginEmail string
+
+func (loginEmail) Name() string                   { return "email" }
+func (loginEmail) Table() tab.Table               { return logins{} }
+func (loginEmail) SQLType() string                { return "text,text" }
+func (loginEmail) NonNull() bool                  { return true }
+func (c loginEmail) Value() (driver.Value, error) { return string(c), nil }
+
+type loginAt int64
+
+func (loginAt) Name() string                   { return "at" }
+func (loginAt) Table() tab.Table               { return logins{} }
+func (loginAt) SQLType() string                { return "int8,bigint" }
+func (loginAt) NonNull() bool                  { return true }
+func (c loginAt) Value() (driver.Value, error) { return int64(c), nil }
+
+type loginsEmailKey struct{}
+
+func (loginsEmailKey) Name() string             { return "logins_email_key" }
+func (loginsEmailKey) Type() tab.ConstraintType { return tab.ConstraintUnique }
+func (loginsEmailKey) Definition() string       { return "UNIQUE (email)" }
+func (loginsEmailKey) Uniques() []tab.Column    { return []tab.Column{loginEmail("")} }
+
+// values returns the driver values of args
+func values(t *testing.T, args []interface{}) []driver.Value {
+	t.Helper()
+	vs := []driver.Value{}
+	for _, a := range args {
+		v, err := tab.ValueOf(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func TestSelectPage(t *testing.T) {
+	cols := []tab.Column{userID(0), userEmail("")}
+	from := `SELECT "id", "email" FROM "app"."users" `
+	checkQueries(t, []query{
+		q("order limit offset", from+`ORDER BY "email" DESC NULLS LAST, "nick" ASC NULLS FIRST LIMIT 10 OFFSET 20`)(
+			SelectPage(cols, nil, Page{Order: []Order{Desc(userEmail("")).NullsLast(), Asc(userNick{}).NullsFirst()}, Limit: 10, Offset: 20})),
+		q("offset only", from+`OFFSET 5`)(
+			SelectPage(cols, nil, Page{Offset: 5})),
+		q("keyset", from+`ORDER BY "id" ASC LIMIT 10`)(
+			SelectPage(cols, nil, Page{Keyset: true, Limit: 10})),
+		q("keyset follows the last term", from+`ORDER BY "email" DESC, "id" DESC`)(
+			SelectPage(cols, nil, Page{Order: []Order{Desc(userEmail(""))}, Keyset: true})),
+		q("keyset ordered by the key", from+`ORDER BY "id" DESC`)(
+			SelectPage(cols, nil, Page{Order: []Order{Desc(userID(0))}, Keyset: true})),
+		q("unique key", `SELECT "email" FROM "logins" ORDER BY "at" ASC, "email" ASC`)(
+			SelectPage([]tab.Column{loginEmail("")}, nil, Page{Order: []Order{Asc(loginAt(0)), Asc(loginEmail(""))}, Keyset: true})),
+		q("mysql nulls", "SELECT `id` FROM `app`.`users` ORDER BY `nick` IS NULL DESC, `nick` ASC LIMIT 18446744073709551615 OFFSET 5")(
+			New(dialect.MySQL).SelectPage(cols[:1], nil, Page{Order: []Order{Asc(userNick{}).NullsFirst()}, Offset: 5})),
+	})
+}
+
+func TestSelectPageWithoutKey(t *testing.T) {
+	cases := []struct {
+		name string
+		cols []tab.Column
+		p    Page
+	}{
+		{"no constraints", []tab.Column{accountID(0)}, Page{Order: []Order{Asc(accountID(0))}, Keyset: true}},
+		{"no order", []tab.Column{loginEmail("")}, Page{Keyset: true}},
+		{"order without the unique key", []tab.Column{loginEmail("")}, Page{Order: []Order{Asc(loginAt(0))}, Keyset: true}},
+		{"after", []tab.Column{loginEmail("")}, Page{Order: []Order{Asc(loginAt(0))}, After: "x"}},
+	}
+	for _, c := range cases {
+		if _, err := SelectPage(c.cols, nil, c.p); err == nil {
+			t.Errorf("%s: got no error", c.name)
+		}
+	}
+	if _, err := Cursor(logins{}, Page{Order: []Order{Asc(loginAt(0))}}); err == nil {
+		t.Error("got a cursor without a unique order")
+	}
+}
+
+func TestSeek(t *testing.T) {
+	last := users{ID: 5, Email: "e"}
+	cases := []struct {
+		name  string
+		order []Order
+		where string
+		by    string
+		args  []driver.Value
+	}{
+		{"key", nil, `(("id" > $1))`, `"id" ASC`, []driver.Value{int64(5)}},
+		{"desc", []Order{Desc(userEmail(""))},
+			`(("email" < $1) OR ("email" = $2 AND "id" < $3))`, `"email" DESC, "id" DESC`, []driver.Value{"e", "e", int64(5)}},
+		{"mixed", []Order{Asc(userEmail("")), Desc(userID(0))},
+			`(("email" > $1) OR ("email" = $2 AND "id" < $3))`, `"email" ASC, "id" DESC`, []driver.Value{"e", "e", int64(5)}},
+	}
+	for _, c := range cases {
+		p := Page{Order: c.order, Limit: 2}
+		token, err := Cursor(last, p)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		p.After = token
+		qr, err := SelectPage([]tab.Column{userID(0)}, pred.IsNotNull(userNick{}), p)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		want := `SELECT "id" FROM "app"."users" WHERE ("nick" IS NOT NULL AND ` + c.where + `) ORDER BY ` + c.by + " LIMIT 2"
+		if qr.SQL() != want {
+			t.Errorf("%s:\n got %s\nwant %s", c.name, qr.SQL(), want)
+		}
+		if got := values(t, qr.Args()); !reflect.DeepEqual(got, c.args) {
+			t.Errorf("%s: got args %v, want %v", c.name, got, c.args)
+		}
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	token, err := Cursor(users{ID: 5, Email: "e"}, Page{Order: []Order{Asc(userEmail(""))}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		token string
+		order []Order
+	}{
+		{"not base64", "!", nil},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("[")), nil},
+		{"other order", token, []Order{Asc(userNick{})}},
+		{"fewer terms", token, nil},
+		{"other direction", token, []Order{Desc(userEmail(""))}},
+		{"other key direction", token, []Order{Asc(userEmail("")), Desc(userID(0))}},
+		{"no directions", base64.RawURLEncoding.EncodeToString([]byte(`{"c":["id"],"v":["i1"]}`)), nil},
+		{"bad value", base64.RawURLEncoding.EncodeToString([]byte(`{"c":["id"],"d":[false],"v":["q1"]}`)), nil},
+		{"bad number", base64.RawURLEncoding.EncodeToString([]byte(`{"c":["id"],"d":[false],"v":["ix"]}`)), nil},
+	}
+	for _, c := range cases {
+		_, err := SelectPage([]tab.Column{userID(0)}, nil, Page{Order: c.order, After: c.token})
+		if err != ErrInvalidCursor {
+			t.Errorf("%s: got %v, want ErrInvalidCursor", c.name, err)
+		}
+	}
+	if _, err := SelectPage([]tab.Column{userID(0)}, nil, Page{Order: []Order{Asc(userEmail(""))}, After: token}); err != nil {
+		t.Errorf("same order: got %v", err)
+	}
+	if _, err := Cursor(users{ID: 5}, Page{Order: []Order{Asc(userNick{})}}); err == nil {
+		t.Error("got a cursor for a NULL position")
+	}
+}
+
+func TestCursorValues(t *testing.T) {
+	now := time.Date(2024, 2, 29, 12, 30, 0, 123456789, time.UTC)
+	for _, v := range []driver.Value{int64(-7), 1.25, true, []byte{0, 0xff}, "a b", "", now} {
+		s, err := encodeValue(v)
+		if err != nil {
+			t.Errorf("%v: %v", v, err)
+			continue
+		}
+		got, err := decodeValue(s)
+		if err != nil || !reflect.DeepEqual(got, v) {
+			t.Errorf("%v: got %v %v through %q", v, got, err, s)
+		}
+	}
+	if _, err := encodeValue(nil); err == nil {
+		t.Error("encoded NULL")
+	}
+	if _, err := encodeValue(uint8(1)); err == nil {
+		t.Error("encoded an unsupported value")
+	}
+	if _, err := decodeValue(""); err != ErrInvalidCursor {
+		t.Errorf("decoded an empty value: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package crud
+
+import (
+	sq "github.com/elgris/sqrl"
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// visibility selects the rows of soft delete tables a Builder reads and writes
+type visibility int
+
+const (
+	liveRows visibility = iota
+	allRows
+	deletedRows
+)
+
+// WithDeleted returns a copy of the Builder seeing the soft deleted rows of tabua.SoftDeleter tables too
+func (b Builder) WithDeleted() Builder {
+	b.deleted = allRows
+	return b
+}
+
+// OnlyDeleted returns a copy of the Builder seeing only the soft deleted rows of tabua.SoftDeleter tables
+func (b Builder) OnlyDeleted() Builder {
+	b.deleted = deletedRows
+	return b
+}
+
+// HardDelete returns a copy of the Builder removing the rows of tabua.SoftDeleter tables instead of marking them,
+// soft deleted rows included unless OnlyDeleted restricts it to them
+func (b Builder) HardDelete() Builder {
+	b.hard = true
+	return b
+}
+
+// visible restricts where to the rows of t the Builder sees, soft deleted rows are left out by default
+func (b Builder) visible(t tab.Table, where pred.Predicate) pred.Predicate {
+	sd, ok := t.(tab.SoftDeleter)
+	if !ok || b.deleted == allRows {
+		return where
+	}
+	p := pred.IsNull(sd.DeletedColumn())
+	if b.deleted == deletedRows {
+		p = pred.IsNotNull(sd.DeletedColumn())
+	}
+	return and(where, p)
+}
+
+// removable restricts where to the rows of t the Builder deletes, a hard delete sees every row unless OnlyDeleted
+func (b Builder) removable(t tab.Table, where pred.Predicate) pred.Predicate {
+	if b.hard && b.deleted != deletedRows {
+		return where
+	}
+	return b.visible(t, where)
+}
+
+// softDelete marks the live rows of t matched by where deleted, keeping the time rows were first deleted
+func softDelete(b Builder, t tab.Table, sd tab.SoftDeleter, where pred.Predicate, returning []tab.Column) (q tab.Querier, err error) {
+	stmt := b.sb.Update(b.q.Q(t)).
+		Set(b.q.Q(sd.DeletedColumn()), sq.Expr("CURRENT_TIMESTAMP"))
+
+	stmt = stmt.Where(b.where(and(where, pred.IsNull(sd.DeletedColumn()))))
+
+	if len(returning) > 0 {
+		rtn, err := b.returning(returning)
+		if err != nil {
+			return nil, err
+		}
+		stmt = stmt.Suffix(rtn)
+	}
+
+	sql, args, err := stmt.ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+	return b.query(t, sql, args)
+}
@@ -0,0 +1,76 @@
+package crud
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// posts is a soft delete table
+type posts struct {
+	ID        postID
+	DeletedAt postDeletedAt
+}
+
+func (posts) Name() string                  { return "posts" }
+func (posts) Constraints() []tab.Constraint { return nil }
+func (p posts) Columns() []tab.Column       { return []tab.Column{p.ID, p.DeletedAt} }
+func (posts) DeletedColumn() tab.Column     { return postDeletedAt{} }
+
+type postID int64
+
+func (postID) Name() string                   { return "id" }
+func (postID) Table() tab.Table               { return posts{} }
+func (postID) SQLType() string                { return "int8,bigint" }
+func (postID) NonNull() bool                  { return true }
+func (c postID) Value() (driver.Value, error) { return int64(c), nil }
+
+type postDeletedAt struct{}
+
+func (postDeletedAt) Name() string                 { return "deleted_at" }
+func (postDeletedAt) Table() tab.Table             { return posts{} }
+func (postDeletedAt) SQLType() string              { return "timestamptz,timestamp with time zone" }
+func (postDeletedAt) NonNull() bool                { return false }
+func (postDeletedAt) Value() (driver.Value, error) { return nil, nil }
+
+func TestSoftDelete(t *testing.T) {
+	p := posts{ID: 1}
+	checkQueries(t, []query{
+		q("delete", `UPDATE "posts" SET "deleted_at" = CURRENT_TIMESTAMP WHERE (("id" = $1) AND "deleted_at" IS NULL)`, p.ID)(
+			Delete(p, p.ID)),
+		q("delete returning", `UPDATE "posts" SET "deleted_at" = CURRENT_TIMESTAMP WHERE (("id" = $1) AND "deleted_at" IS NULL) RETURNING "deleted_at"`, p.ID)(
+			DeleteR(p, []tab.Column{p.DeletedAt}, p.ID)),
+		q("hard delete", `DELETE FROM "posts" WHERE ("id" = $1)`, p.ID)(
+			std.HardDelete().Delete(p, p.ID)),
+		q("hard delete returning", `DELETE FROM "posts" WHERE ("id" = $1) RETURNING "id"`, p.ID)(
+			std.HardDelete().DeleteR(p, []tab.Column{p.ID}, p.ID)),
+		q("hard delete with deleted", `DELETE FROM "posts" WHERE ("id" = $1)`, p.ID)(
+			std.WithDeleted().HardDelete().Delete(p, p.ID)),
+		q("purge deleted", `DELETE FROM "posts" WHERE (("id" = $1) AND "deleted_at" IS NOT NULL)`, p.ID)(
+			std.OnlyDeleted().HardDelete().Delete(p, p.ID)),
+		q("purge all deleted", `DELETE FROM "posts" WHERE "deleted_at" IS NOT NULL`)(
+			std.OnlyDeleted().HardDelete().AllRows().Delete(p)),
+		q("hard delete of a plain table", `DELETE FROM "app"."users" WHERE ("id" = $1)`, userID(1))(
+			std.HardDelete().Delete(users{}, userID(1))),
+	})
+}
+
+func TestSoftDeleteVisibility(t *testing.T) {
+	p := posts{ID: 1}
+	cols := []tab.Column{p.ID}
+	checkQueries(t, []query{
+		q("select", `SELECT "id" FROM "posts" WHERE (("id" = $1) AND "deleted_at" IS NULL)`, p.ID)(
+			Select(cols, p.ID)),
+		q("select all", `SELECT "id" FROM "posts" WHERE "deleted_at" IS NULL`)(
+			Select(cols)),
+		q("select with deleted", `SELECT "id" FROM "posts" WHERE ("id" = $1)`, p.ID)(
+			std.WithDeleted().Select(cols, p.ID)),
+		q("select only deleted", `SELECT "id" FROM "posts" WHERE (("id" = $1) AND "deleted_at" IS NOT NULL)`, p.ID)(
+			std.OnlyDeleted().Select(cols, p.ID)),
+		q("restore", `UPDATE "posts" SET "deleted_at" = $1 WHERE (("id" = $2) AND "deleted_at" IS NOT NULL)`, p.DeletedAt, p.ID)(
+			std.OnlyDeleted().Update([]tab.Column{p.DeletedAt}, p.ID)),
+		q("update", `UPDATE "posts" SET "id" = $1 WHERE (("id" = $2) AND "deleted_at" IS NULL)`, postID(2), p.ID)(
+			Update([]tab.Column{postID(2)}, p.ID)),
+	})
+}
@@ -0,0 +1,178 @@
+package crud
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultStmtCacheSize is the number of statements a StmtCache keeps prepared by default
+const DefaultStmtCacheSize = 256
+
+// StmtCache prepares the statements run on a *sqlx.DB lazily and reuses them across goroutines,
+// keyed by pool and SQL, closing the least recently used ones past its size
+type StmtCache struct {
+	mu    sync.Mutex
+	size  int
+	lru   *list.List
+	items map[stmtKey]*list.Element
+	stats CacheStats
+}
+
+// CacheStats counts the lookups of a StmtCache
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Evictions counts statements closed to stay within size or by Close
+	Evictions uint64
+	// Invalidations counts statements dropped after a schema change invalidated them
+	Invalidations uint64
+	// Size is the number of statements prepared
+	Size int
+}
+
+type stmtKey struct {
+	db  *sqlx.DB
+	sql string
+}
+
+// cachedStmt is closed once evicted and no longer in use
+type cachedStmt struct {
+	key     stmtKey
+	stmt    *sqlx.Stmt
+	refs    int
+	evicted bool
+}
+
+// NewStmtCache returns a StmtCache keeping up to size statements prepared, DefaultStmtCacheSize when size < 1
+func NewStmtCache(size int) *StmtCache {
+	if size < 1 {
+		size = DefaultStmtCacheSize
+	}
+	return &StmtCache{
+		size:  size,
+		lru:   list.New(),
+		items: map[stmtKey]*list.Element{},
+	}
+}
+
+// Cache returns a copy of the Builder running statements on a *sqlx.DB through c,
+// transactions and other executors prepare nothing
+func (b Builder) Cache(c *StmtCache) Builder {
+	b.cache = c
+	return b
+}
+
+// Stats returns the lookup counts of c
+func (c *StmtCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Size = c.lru.Len()
+	return s
+}
+
+// Close closes every statement not in use, the others once they're done
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var err error
+	for c.lru.Len() > 0 {
+		if cerr := c.evict(c.lru.Back()); cerr != nil && err == nil {
+			err = cerr
+		}
+		c.stats.Evictions++
+	}
+	return err
+}
+
+// with runs fn with the statement of query on db, preparing it on a miss
+// A statement invalidated by a schema change is dropped and prepared again once
+func (c *StmtCache) with(ctx context.Context, db *sqlx.DB, query string, fn func(*sqlx.Stmt) error) error {
+	key := stmtKey{db, query}
+	for retried := false; ; retried = true {
+		cs, err := c.acquire(ctx, key)
+		if err != nil {
+			return err
+		}
+		err = fn(cs.stmt)
+		if err != nil && !retried && invalidated(err) {
+			c.release(cs, true)
+			continue
+		}
+		c.release(cs, false)
+		return err
+	}
+}
+
+func (c *StmtCache) acquire(ctx context.Context, key stmtKey) (*cachedStmt, error) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.stats.Hits++
+		c.lru.MoveToFront(el)
+		cs := el.Value.(*cachedStmt)
+		cs.refs++
+		c.mu.Unlock()
+		return cs, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// prepare without the lock, a slow server doesn't block hits
+	stmt, err := key.db.PreparexContext(ctx, key.sql)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		// another goroutine prepared it first
+		stmt.Close()
+		c.lru.MoveToFront(el)
+		cs := el.Value.(*cachedStmt)
+		cs.refs++
+		return cs, nil
+	}
+	cs := &cachedStmt{key: key, stmt: stmt, refs: 1}
+	c.items[key] = c.lru.PushFront(cs)
+	for c.lru.Len() > c.size {
+		c.evict(c.lru.Back())
+		c.stats.Evictions++
+	}
+	return cs, nil
+}
+
+func (c *StmtCache) release(cs *cachedStmt, invalid bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cs.refs--
+	if invalid && !cs.evicted {
+		if el, ok := c.items[cs.key]; ok && el.Value == cs {
+			c.evict(el)
+			c.stats.Invalidations++
+		}
+	}
+	if cs.evicted && cs.refs < 1 {
+		cs.stmt.Close()
+	}
+}
+
+// evict removes el from the cache, its statement is closed once no longer in use
+func (c *StmtCache) evict(el *list.Element) error {
+	cs := c.lru.Remove(el).(*cachedStmt)
+	delete(c.items, cs.key)
+	cs.evicted = true
+	if cs.refs < 1 {
+		return cs.stmt.Close()
+	}
+	return nil
+}
+
+// invalidated reports if err is postgres refusing a statement prepared before a schema change
+func invalidated(err error) bool {
+	return strings.Contains(err.Error(), "cached plan must not change result type")
+}
@@ -0,0 +1,135 @@
+package crud
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+func TestStmtCache(t *testing.T) {
+	f := &fakeDB{}
+	db := open(f)
+	c := NewStmtCache(2)
+	b := std.Cache(c)
+	var got userRow
+	f.cols, f.rows = []string{"id", "email"}, [][]driver.Value{{int64(1), "a"}}
+	for i := 0; i < 3; i++ {
+		if err := b.Get(context.Background(), db, &got, []tab.Column{userID(0), userEmail("")}, userID(1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f.prepared != 1 {
+		t.Errorf("prepared %d statements for one query, want 1", f.prepared)
+	}
+	if s := c.Stats(); s.Hits != 2 || s.Misses != 1 || s.Size != 1 {
+		t.Errorf("got stats %+v", s)
+	}
+
+	// transactions prepare nothing
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	q, _ := Delete(users{}, userID(1))
+	if _, err := b.Exec(context.Background(), tx, q); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Stats(); s.Hits != 2 || s.Misses != 1 {
+		t.Errorf("got stats %+v after a transaction", s)
+	}
+}
+
+func TestStmtCacheLRU(t *testing.T) {
+	f := &fakeDB{}
+	db := open(f)
+	c := NewStmtCache(2)
+	queries := []string{"SELECT 1", "SELECT 2", "SELECT 1", "SELECT 3", "SELECT 2"}
+	for _, query := range queries {
+		err := c.with(context.Background(), db, query, func(s *sqlx.Stmt) error {
+			_, err := s.ExecContext(context.Background())
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// SELECT 2 was the least recently used when SELECT 3 came, then SELECT 1 for SELECT 2
+	s := c.Stats()
+	if s.Hits != 1 || s.Misses != 4 || s.Evictions != 2 || s.Size != 2 {
+		t.Errorf("got stats %+v", s)
+	}
+	if f.closed != 2 {
+		t.Errorf("closed %d statements, want the 2 evicted", f.closed)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Stats(); s.Evictions != 4 || s.Size != 0 || f.closed != 4 {
+		t.Errorf("got stats %+v and %d statements closed after Close", s, f.closed)
+	}
+}
+
+func TestStmtCacheRefs(t *testing.T) {
+	f := &fakeDB{}
+	db := open(f)
+	c := NewStmtCache(1)
+	in, err := c.acquire(context.Background(), stmtKey{db, "SELECT 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// evicted while in use, closed once released
+	c.Close()
+	if f.closed != 0 {
+		t.Errorf("closed a statement in use")
+	}
+	c.release(in, false)
+	if f.closed != 1 {
+		t.Errorf("closed %d statements after release, want 1", f.closed)
+	}
+
+	again, _ := c.acquire(context.Background(), stmtKey{db, "SELECT 1"})
+	other, _ := c.acquire(context.Background(), stmtKey{db, "SELECT 2"})
+	if f.closed != 1 {
+		t.Errorf("closed a statement in use past the cache size")
+	}
+	c.release(again, false)
+	c.release(other, false)
+	if f.closed != 2 || c.Stats().Size != 1 {
+		t.Errorf("closed %d statements, want the evicted one, size %d", f.closed, c.Stats().Size)
+	}
+}
+
+func TestStmtCacheInvalidation(t *testing.T) {
+	invalid := errors.New(`pq: cached plan must not change result type`)
+	fails := 1
+	f := &fakeDB{fail: func(string) error {
+		if fails > 0 {
+			fails--
+			return invalid
+		}
+		return nil
+	}}
+	db := open(f)
+	c := NewStmtCache(0)
+	q, _ := Delete(users{}, userID(1))
+	if _, err := std.Cache(c).Exec(context.Background(), db, q); err != nil {
+		t.Fatal(err)
+	}
+	if s := c.Stats(); s.Invalidations != 1 || s.Misses != 2 || s.Size != 1 {
+		t.Errorf("got stats %+v", s)
+	}
+	if f.prepared != 2 {
+		t.Errorf("prepared %d statements, want the statement prepared again", f.prepared)
+	}
+
+	// a second invalidation is returned
+	fails = 2
+	if _, err := std.Cache(c).Exec(context.Background(), db, q); !errors.Is(err, invalid) {
+		t.Errorf("got %v, want the invalidation after one retry", err)
+	}
+}
@@ -0,0 +1,141 @@
+package crud
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// DefaultTxBackoff is the wait before the first retry of a transaction, doubled on each one
+const DefaultTxBackoff = 10 * time.Millisecond
+
+// DefaultTxMaxBackoff is the longest wait before a retry of a transaction
+const DefaultTxMaxBackoff = time.Second
+
+// TxOptions configures InTx
+type TxOptions struct {
+	sql.TxOptions
+	// Retries is how many times a transaction failing to serialize or deadlocking runs again, none by default
+	Retries int
+	// Backoff is the wait before the first retry, DefaultTxBackoff when zero
+	Backoff time.Duration
+	// MaxBackoff caps the doubled Backoff, DefaultTxMaxBackoff when zero
+	MaxBackoff time.Duration
+	// Log receives a warning for each retry, slog.Default() when nil
+	Log *slog.Logger
+}
+
+var savepoints uint64
+
+// InTx runs fn in a transaction begun on db, committed when fn returns nil and rolled back on an error or panic
+// With a *sqlx.Tx as db, fn runs within a SAVEPOINT released on success and rolled back to otherwise,
+// and the outermost transaction handles retries. opts may be nil
+func InTx(ctx context.Context, db sqlx.ExtContext, opts *TxOptions, fn func(tx *sqlx.Tx) error) error {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	switch ex := db.(type) {
+	case *sqlx.Tx:
+		return savepoint(ctx, ex, fn)
+	case *sqlx.DB:
+		backoff, maxBackoff := opts.Backoff, opts.MaxBackoff
+		if backoff <= 0 {
+			backoff = DefaultTxBackoff
+		}
+		if maxBackoff <= 0 {
+			maxBackoff = DefaultTxMaxBackoff
+		}
+		for attempt := 0; ; attempt++ {
+			err := inTx(ctx, ex, &opts.TxOptions, fn)
+			code, retry := retryable(err)
+			if !retry || attempt >= opts.Retries {
+				return err
+			}
+			// full jitter spreads out the transactions that conflicted
+			wait := time.Duration(rand.Int63n(int64(ceiling(backoff, maxBackoff, attempt)) + 1))
+			log := opts.Log
+			if log == nil {
+				log = slog.Default()
+			}
+			log.WarnContext(ctx, "retrying transaction", "attempt", attempt+1, "code", code, "backoff", wait, "error", err.Error())
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(wait):
+			}
+		}
+	}
+	return fmt.Errorf("transactions begin on a *sqlx.DB or nest in a *sqlx.Tx, not a %T", db)
+}
+
+// ceiling returns the longest wait before the retry following attempt, backoff doubled for each attempt up to limit
+func ceiling(backoff, limit time.Duration, attempt int) time.Duration {
+	for i := 0; i < attempt && backoff < limit; i++ {
+		if backoff > limit/2 {
+			return limit
+		}
+		backoff <<= 1
+	}
+	return min(backoff, limit)
+}
+
+func inTx(ctx context.Context, db *sqlx.DB, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) (err error) {
+	tx, err := db.BeginTxx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func savepoint(ctx context.Context, tx *sqlx.Tx, fn func(tx *sqlx.Tx) error) (err error) {
+	name := "tabua_sp_" + strconv.FormatUint(atomic.AddUint64(&savepoints, 1), 10)
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if _, rerr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rerr != nil {
+			return errors.Join(err, rerr)
+		}
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// retryable reports if err failed to serialize or deadlocked, the transaction can run again
+func retryable(err error) (string, bool) {
+	var pqErr *pq.Error
+	if err == nil || !errors.As(err, &pqErr) {
+		return "", false
+	}
+	switch pqErr.Code {
+	case "40001", "40P01":
+		return string(pqErr.Code), true
+	}
+	return "", false
+}
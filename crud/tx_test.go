@@ -0,0 +1,158 @@
+package crud
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+func TestInTx(t *testing.T) {
+	f := &fakeDB{}
+	db := open(f)
+	err := InTx(context.Background(), db, nil, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("SELECT 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := f.statements(), []string{"BEGIN", "SELECT 1", "COMMIT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("commit: got %q, want %q", got, want)
+	}
+
+	f.log = nil
+	failed := errors.New("failed")
+	if err := InTx(context.Background(), db, nil, func(*sqlx.Tx) error { return failed }); err != failed {
+		t.Errorf("rollback: got %v, want %v", err, failed)
+	}
+	if got, want := f.statements(), []string{"BEGIN", "ROLLBACK"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rollback: got %q, want %q", got, want)
+	}
+
+	f.log = nil
+	func() {
+		defer func() {
+			if p := recover(); p != "panicked" {
+				t.Errorf("panic: recovered %v", p)
+			}
+		}()
+		InTx(context.Background(), db, nil, func(*sqlx.Tx) error { panic("panicked") })
+	}()
+	if got, want := f.statements(), []string{"BEGIN", "ROLLBACK"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("panic: got %q, want %q", got, want)
+	}
+
+	err = InTx(context.Background(), struct{ *sqlx.DB }{db}, nil, func(*sqlx.Tx) error { return nil })
+	if _, ok := err.(tab.QueryGenerationError); err == nil || ok {
+		t.Errorf("other executor: got %#v, want a plain error", err)
+	}
+}
+
+func TestInTxSavepoint(t *testing.T) {
+	f := &fakeDB{}
+	failed := errors.New("failed")
+	err := InTx(context.Background(), open(f), nil, func(tx *sqlx.Tx) error {
+		if err := InTx(context.Background(), tx, nil, func(*sqlx.Tx) error { return nil }); err != nil {
+			return err
+		}
+		if err := InTx(context.Background(), tx, nil, func(*sqlx.Tx) error { return failed }); err != failed {
+			t.Errorf("got %v, want %v", err, failed)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"BEGIN", "SAVEPOINT", "RELEASE SAVEPOINT", "SAVEPOINT", "ROLLBACK TO SAVEPOINT", "COMMIT"}
+	got := f.statements()
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("got %q, want %q", got[i], want[i])
+		}
+	}
+	if name := strings.TrimPrefix(got[1], "SAVEPOINT "); got[2] != "RELEASE SAVEPOINT "+name || got[3] == got[1] {
+		t.Errorf("savepoints %q", got)
+	}
+}
+
+func TestInTxRetries(t *testing.T) {
+	quiet := slog.New(slog.NewTextHandler(io.Discard, nil))
+	opts := &TxOptions{Retries: 2, Backoff: time.Nanosecond, Log: quiet}
+	cases := []struct {
+		name  string
+		fails int
+		err   error
+		runs  int
+		fail  bool
+	}{
+		{"serialization", 1, &pq.Error{Code: "40001"}, 2, false},
+		{"deadlock", 2, &pq.Error{Code: "40P01"}, 3, false},
+		{"out of retries", 3, &pq.Error{Code: "40001"}, 3, true},
+		{"unique violation", 1, &pq.Error{Code: "23505"}, 1, true},
+		{"other error", 1, errors.New("failed"), 1, true},
+	}
+	for _, c := range cases {
+		fails := c.fails
+		f := &fakeDB{fail: func(query string) error {
+			if query == "COMMIT" && fails > 0 {
+				fails--
+				return c.err
+			}
+			return nil
+		}}
+		runs := 0
+		err := InTx(context.Background(), open(f), opts, func(*sqlx.Tx) error {
+			runs++
+			return nil
+		})
+		if runs != c.runs || (err != nil) != c.fail {
+			t.Errorf("%s: ran %d times with %v, want %d", c.name, runs, err, c.runs)
+		}
+	}
+
+	f := &fakeDB{fail: func(string) error { return &pq.Error{Code: "40001"} }}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	runs := 0
+	InTx(ctx, open(f), &TxOptions{Retries: 5, Backoff: time.Hour, Log: quiet}, func(*sqlx.Tx) error {
+		runs++
+		return nil
+	})
+	if runs > 1 {
+		t.Errorf("retried %d times after the context was done", runs-1)
+	}
+}
+
+func TestCeiling(t *testing.T) {
+	cases := []struct {
+		backoff, limit time.Duration
+		attempt        int
+		want           time.Duration
+	}{
+		{10 * time.Millisecond, time.Second, 0, 10 * time.Millisecond},
+		{10 * time.Millisecond, time.Second, 3, 80 * time.Millisecond},
+		{10 * time.Millisecond, time.Second, 7, time.Second},
+		{10 * time.Millisecond, time.Second, 64, time.Second},
+		{10 * time.Millisecond, time.Second, math.MaxInt, time.Second},
+		{time.Duration(math.MaxInt64 / 3), math.MaxInt64, 2, math.MaxInt64},
+		{time.Minute, time.Second, 1, time.Second},
+	}
+	for _, c := range cases {
+		if got := ceiling(c.backoff, c.limit, c.attempt); got != c.want {
+			t.Errorf("%v up to %v, attempt %d: got %v, want %v", c.backoff, c.limit, c.attempt, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package crud
+
+import (
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// OnConflict describes how an upsert resolves rows that already exist
+type OnConflict struct {
+	// Columns of the unique index conflicts happen on
+	Columns []tab.Column
+	// Constraint names the unique or exclusion constraint conflicts happen on, used instead of Columns
+	Constraint tab.Constrainer
+	// Where is the predicate of the partial unique index on Columns
+	Where pred.Predicate
+	// Update columns are set to the value of the row being inserted, none means DO NOTHING
+	Update []tab.Column
+	// UpdateWhere limits the updated rows to those it matches
+	UpdateWhere pred.Predicate
+}
+
+// OnColumns returns an OnConflict for the unique index on cols
+func OnColumns(cols ...tab.Column) OnConflict {
+	return OnConflict{Columns: cols}
+}
+
+// OnConstraint returns an OnConflict for a generated constraint
+func OnConstraint(c tab.Constrainer) OnConflict {
+	return OnConflict{Constraint: c}
+}
+
+// DoUpdate returns a copy of o updating cols
+func (o OnConflict) DoUpdate(cols ...tab.Column) OnConflict {
+	o.Update = cols
+	return o
+}
+
+// UpdateIf returns a copy of o only updating rows matched by p
+func (o OnConflict) UpdateIf(p pred.Predicate) OnConflict {
+	o.UpdateWhere = p
+	return o
+}
+
+// UpsertOn generates query to upsert table, resolving conflicts as described by on
+func (b Builder) UpsertOn(cols []tab.Column, on OnConflict) (q tab.Querier, err error) {
+	return upsertOnly(b, cols, on)
+}
+
+// UpsertOnR generates query to upsert table while returning selected columns
+func (b Builder) UpsertOnR(cols []tab.Column, on OnConflict, returning ...tab.Column) (q tab.Querier, err error) {
+	return upsertOnly(b, cols, on, returning...)
+}
+
+// UpsertManyOn generates queries to upsert rows, resolving conflicts as described by on
+func (b Builder) UpsertManyOn(rows [][]tab.Column, on OnConflict) ([]tab.Querier, error) {
+	return insertMany(b, rows, &on)
+}
+
+// UpsertManyOnR generates queries to upsert rows while returning selected columns
+func (b Builder) UpsertManyOnR(rows [][]tab.Column, on OnConflict, returning ...tab.Column) ([]tab.Querier, error) {
+	return insertMany(b, rows, &on, returning...)
+}
+
+// UpsertOn generates query to upsert table, resolving conflicts as described by on
+func UpsertOn(cols []tab.Column, on OnConflict) (q tab.Querier, err error) {
+	return std.UpsertOn(cols, on)
+}
+
+// UpsertOnR generates query to upsert table while returning selected columns
+func UpsertOnR(cols []tab.Column, on OnConflict, returning ...tab.Column) (q tab.Querier, err error) {
+	return std.UpsertOnR(cols, on, returning...)
+}
+
+// UpsertManyOn generates queries to upsert rows, resolving conflicts as described by on
+func UpsertManyOn(rows [][]tab.Column, on OnConflict) ([]tab.Querier, error) {
+	return std.UpsertManyOn(rows, on)
+}
+
+// UpsertManyOnR generates queries to upsert rows while returning selected columns
+func UpsertManyOnR(rows [][]tab.Column, on OnConflict, returning ...tab.Column) ([]tab.Querier, error) {
+	return std.UpsertManyOnR(rows, on, returning...)
+}
+
+// constraintColumns returns the columns of a unique or primary key constraint
+func constraintColumns(c tab.Constrainer) []tab.Column {
+	switch c := c.(type) {
+	case tab.PKConstrainer:
+		return c.Keys()
+	case tab.UniqueConstrainer:
+		return c.Uniques()
+	}
+	return nil
+}
+
+// conflict renders on for the Builder's dialect
+func (b Builder) conflict(on OnConflict) (string, []interface{}, error) {
+	c := tab.Conflict{
+		Target: b.q.Columns(on.Columns...),
+		Update: b.q.Columns(on.Update...),
+	}
+	if on.Constraint != nil {
+		c.Constraint = b.d.QuoteIdent(on.Constraint.Name())
+		// the key columns let dialects without named targets fall back to them
+		if len(c.Target) < 1 {
+			c.Target = b.q.Columns(constraintColumns(on.Constraint)...)
+		}
+	}
+	if on.Where != nil {
+		sql, args, err := on.Where.Render(b.d)
+		if err != nil {
+			return "", nil, err
+		}
+		c.TargetWhere = tab.Sq{S: sql, A: args}
+	}
+	if on.UpdateWhere != nil {
+		sql, args, err := on.UpdateWhere.Render(b.d)
+		if err != nil {
+			return "", nil, err
+		}
+		c.UpdateWhere = tab.Sq{S: sql, A: args}
+	}
+	return b.d.Upsert(c)
+}
@@ -0,0 +1,67 @@
+package crud
+
+import (
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+func TestUpsertOn(t *testing.T) {
+	u := users{ID: 1, Email: "a@b.c", Nick: nick("a")}
+	cols := []tab.Column{u.ID, u.Email, u.Nick}
+	insert := `INSERT INTO "app"."users" ("id","email","nick") VALUES ($1,$2,$3) `
+	checkQueries(t, []query{
+		q("upsert", insert+`ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email"`, u.ID, u.Email, u.Nick)(
+			Upsert(cols, u.ID, []tab.Column{u.Email})),
+		q("do nothing", insert+`ON CONFLICT ("id") DO NOTHING`, u.ID, u.Email, u.Nick)(
+			UpsertOn(cols, OnColumns(u.ID))),
+		q("any conflict", insert+`ON CONFLICT DO NOTHING`, u.ID, u.Email, u.Nick)(
+			UpsertOn(cols, OnConflict{})),
+		q("composite", insert+`ON CONFLICT ("email","nick") DO UPDATE SET "id" = EXCLUDED."id"`, u.ID, u.Email, u.Nick)(
+			UpsertOn(cols, OnColumns(u.Email, u.Nick).DoUpdate(u.ID))),
+		q("constraint", insert+`ON CONFLICT ON CONSTRAINT "users_email_key" DO UPDATE SET "nick" = EXCLUDED."nick"`, u.ID, u.Email, u.Nick)(
+			UpsertOn(cols, OnConstraint(usersEmailKey{}).DoUpdate(u.Nick))),
+		q("partial index", insert+`ON CONFLICT ("email") WHERE "nick" IS NOT NULL DO NOTHING`, u.ID, u.Email, u.Nick)(
+			UpsertOn(cols, OnConflict{Columns: []tab.Column{u.Email}, Where: pred.IsNotNull(u.Nick)})),
+		q("conditional update", insert+`ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email" WHERE "id" > $4`, u.ID, u.Email, u.Nick, userID(0))(
+			UpsertOn(cols, OnColumns(u.ID).DoUpdate(u.Email).UpdateIf(pred.Gt(userID(0))))),
+		q("returning", insert+`ON CONFLICT ("id") DO NOTHING RETURNING "id"`, u.ID, u.Email, u.Nick)(
+			UpsertOnR(cols, OnColumns(u.ID), u.ID)),
+	})
+}
+
+func TestUpsertOnDialects(t *testing.T) {
+	u := users{ID: 1, Email: "a@b.c"}
+	cols := []tab.Column{u.ID, u.Email}
+	my := New(dialect.MySQL)
+	lite := New(dialect.SQLite)
+	checkQueries(t, []query{
+		q("mysql update", "INSERT INTO `app`.`users` (`id`,`email`) VALUES (?,?) ON DUPLICATE KEY UPDATE `email` = VALUES(`email`)", u.ID, u.Email)(
+			my.UpsertOn(cols, OnColumns(u.ID).DoUpdate(u.Email))),
+		q("mysql do nothing", "INSERT INTO `app`.`users` (`id`,`email`) VALUES (?,?) ON DUPLICATE KEY UPDATE `id` = `id`", u.ID, u.Email)(
+			my.UpsertOn(cols, OnColumns(u.ID))),
+		q("sqlite constraint", `INSERT INTO "app"."users" ("id","email") VALUES (?,?) ON CONFLICT ("email") DO UPDATE SET "id" = EXCLUDED."id"`, u.ID, u.Email)(
+			lite.UpsertOn(cols, OnConstraint(usersEmailKey{}).DoUpdate(u.ID))),
+	})
+
+	cases := []struct {
+		name string
+		b    Builder
+		on   OnConflict
+	}{
+		{"mysql constraint", my, OnConstraint(usersEmailKey{})},
+		{"mysql condition", my, OnColumns(u.ID).DoUpdate(u.Email).UpdateIf(pred.Gt(u.ID))},
+		{"mysql no target", my, OnConflict{}},
+		{"update without target", std, OnConflict{Update: []tab.Column{u.Email}}},
+		{"nothing with condition", std, OnColumns(u.ID).UpdateIf(pred.Gt(u.ID))},
+		{"where without columns", std, OnConflict{Where: pred.Gt(u.ID)}},
+	}
+	for _, c := range cases {
+		_, err := c.b.UpsertOn(cols, c.on)
+		if _, ok := err.(tab.QueryGenerationError); !ok {
+			t.Errorf("%s: got %v, want a QueryGenerationError", c.name, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package crud
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"time"
+
+	sq "github.com/elgris/sqrl"
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/op"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// ErrStaleRow is returned when an update of a tabua.Versioned table matched no row,
+// it was changed or deleted since its version was read
+type ErrStaleRow struct {
+	Table tab.Table
+	// Key holds the conditions of the update, empty for UpdateWhere
+	Key []tab.Column
+}
+
+func (e ErrStaleRow) Error() string {
+	keys := []string{}
+	for _, k := range e.Key {
+		keys = append(keys, k.Name())
+	}
+	return "stale row of " + op.Q(e.Table) + " (" + strings.Join(keys, ", ") + ")"
+}
+
+// lock holds the version column of a tabua.Versioned table updated by a query
+type lock struct {
+	col  tab.Column
+	bump sq.Sqlizer
+	// check matches the version set by the update, nil when the conditions hold it
+	check pred.Predicate
+	// checked is whether the update only matches rows still at the version it was given
+	checked bool
+}
+
+// version takes the version column out of cols, the update bumps it and only matches
+// rows still at the version given in cols or key, the conditions of the update
+// An update by conditions must give the version, an UpdateWhere only bumps it
+func version(b Builder, t tab.Table, cols []tab.Column, key []tab.Column) ([]tab.Column, *lock, error) {
+	v, ok := t.(tab.Versioned)
+	if !ok {
+		return cols, nil, nil
+	}
+	name := v.VersionColumn().Name()
+	lk := &lock{col: v.VersionColumn()}
+	rest := make([]tab.Column, 0, len(cols))
+	for _, c := range cols {
+		if c.Name() == name {
+			lk.col = c
+			lk.check = pred.Eq(c)
+			lk.checked = true
+			continue
+		}
+		rest = append(rest, c)
+	}
+	if len(rest) < 1 {
+		return nil, nil, tab.QueryGenerationError{Message: "No columns to update besides the version of " + t.Name()}
+	}
+	if c, ok := columnNamed(key, name); ok {
+		// the conditions match the version already
+		lk.col, lk.check, lk.checked = c, nil, true
+	} else if !lk.checked && len(key) > 0 {
+		return nil, nil, tab.QueryGenerationError{Message: "update of " + t.Name() + " needs its version " + name + " among the columns or conditions"}
+	}
+
+	val, err := tab.ValueOf(lk.col)
+	if err != nil {
+		return nil, nil, err
+	}
+	if lk.checked && unset(val) {
+		return nil, nil, tab.QueryGenerationError{Message: "version " + name + " of " + t.Name() + " is unset, read it with the row before updating"}
+	}
+	switch val.(type) {
+	case int64:
+		lk.bump = sq.Expr(b.q.Q(lk.col) + " + 1")
+	case time.Time:
+		lk.bump = sq.Expr("CURRENT_TIMESTAMP")
+	default:
+		return nil, nil, tab.QueryGenerationError{Message: "version column " + name + " of " + t.Name() + " is not an integer or a timestamp"}
+	}
+	return rest, lk, nil
+}
+
+// unset reports if a version is NULL or zero, as in a row that wasn't read
+func unset(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case int64:
+		return v == 0
+	case time.Time:
+		return v.IsZero()
+	}
+	return false
+}
+
+// checked is an update of a tabua.Versioned table, executors return ErrStaleRow when it matches no row
+type checked struct {
+	tab.Querier
+	stale ErrStaleRow
+}
+
+// staleQuery marks q as checked when the update matches rows at a given version
+func staleQuery(q tab.Querier, lk *lock, t tab.Table, key []tab.Column) tab.Querier {
+	if lk == nil || !lk.checked {
+		return q
+	}
+	return checked{q, ErrStaleRow{Table: t, Key: key}}
+}
+
+// staleResult returns ErrStaleRow for a checked update that affected no row
+func staleResult(q tab.Querier, res sql.Result) error {
+	c, ok := q.(checked)
+	if !ok {
+		return nil
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n < 1 {
+		return c.stale
+	}
+	return nil
+}
+
+// staleRow replaces sql.ErrNoRows of a checked update with ErrStaleRow
+func staleRow(q tab.Querier, err error) error {
+	if c, ok := q.(checked); ok && err == sql.ErrNoRows {
+		return c.stale
+	}
+	return err
+}
+
+// staleRows returns ErrStaleRow when a checked update appended no row to dest
+func staleRows(q tab.Querier, dest interface{}, before int) error {
+	c, ok := q.(checked)
+	if !ok {
+		return nil
+	}
+	if sliceLen(dest) <= before {
+		return c.stale
+	}
+	return nil
+}
+
+func sliceLen(dest interface{}) int {
+	v := reflect.ValueOf(dest)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice {
+		return 0
+	}
+	return v.Len()
+}
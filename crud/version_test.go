@@ -0,0 +1,136 @@
+package crud
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/pred"
+)
+
+// docs is locked by an integer version
+type docs struct {
+	ID      docID
+	Body    docBody
+	Version docVersion
+}
+
+func (docs) Name() string                  { return "docs" }
+func (docs) Constraints() []tab.Constraint { return nil }
+func (d docs) Columns() []tab.Column       { return []tab.Column{d.ID, d.Body, d.Version} }
+func (d docs) VersionColumn() tab.Column   { return d.Version }
+
+type docID int64
+
+func (docID) Name() string                   { return "id" }
+func (docID) Table() tab.Table               { return docs{} }
+func (docID) SQLType() string                { return "int8,bigint" }
+func (docID) NonNull() bool                  { return true }
+func (c docID) Value() (driver.Value, error) { return int64(c), nil }
+
+type docBody string
+
+func (docBody) Name() string                   { return "body" }
+func (docBody) Table() tab.Table               { return docs{} }
+func (docBody) SQLType() string                { return "text,text" }
+func (docBody) NonNull() bool                  { return true }
+func (c docBody) Value() (driver.Value, error) { return string(c), nil }
+
+type docVersion int64
+
+func (docVersion) Name() string                   { return "version" }
+func (docVersion) Table() tab.Table               { return docs{} }
+func (docVersion) SQLType() string                { return "int4,integer" }
+func (docVersion) NonNull() bool                  { return true }
+func (c docVersion) Value() (driver.Value, error) { return int64(c), nil }
+
+// notes is locked by the time it was last updated
+type notes struct {
+	ID        noteID
+	UpdatedAt noteUpdatedAt
+}
+
+func (notes) Name() string                  { return "notes" }
+func (notes) Constraints() []tab.Constraint { return nil }
+func (n notes) Columns() []tab.Column       { return []tab.Column{n.ID, n.UpdatedAt} }
+func (n notes) VersionColumn() tab.Column   { return n.UpdatedAt }
+
+type noteID int64
+
+func (noteID) Name() string                   { return "id" }
+func (noteID) Table() tab.Table               { return notes{} }
+func (noteID) SQLType() string                { return "int8,bigint" }
+func (noteID) NonNull() bool                  { return true }
+func (c noteID) Value() (driver.Value, error) { return int64(c), nil }
+
+type noteUpdatedAt time.Time
+
+func (noteUpdatedAt) Name() string                   { return "updated_at" }
+func (noteUpdatedAt) Table() tab.Table               { return notes{} }
+func (noteUpdatedAt) SQLType() string                { return "timestamptz,timestamp with time zone" }
+func (noteUpdatedAt) NonNull() bool                  { return true }
+func (c noteUpdatedAt) Value() (driver.Value, error) { return time.Time(c), nil }
+
+func TestVersion(t *testing.T) {
+	d := docs{ID: 1, Body: "b", Version: 3}
+	at := noteUpdatedAt(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	checkQueries(t, []query{
+		q("version set", `UPDATE "docs" SET "body" = $1, "version" = "version" + 1 WHERE (("id" = $2) AND "version" = $3)`, d.Body, d.ID, d.Version)(
+			Update([]tab.Column{d.Body, d.Version}, d.ID)),
+		q("version condition", `UPDATE "docs" SET "body" = $1, "version" = "version" + 1 WHERE ("id" = $2 AND "version" = $3)`, d.Body, d.ID, d.Version)(
+			Update([]tab.Column{d.Body}, d.ID, d.Version)),
+		q("version set and condition", `UPDATE "docs" SET "body" = $1, "version" = "version" + 1 WHERE ("id" = $2 AND "version" = $3)`, d.Body, d.ID, d.Version)(
+			Update([]tab.Column{d.Body, d.Version}, d.ID, d.Version)),
+		q("returning", `UPDATE "docs" SET "body" = $1, "version" = "version" + 1 WHERE ("id" = $2 AND "version" = $3) RETURNING "version"`, d.Body, d.ID, d.Version)(
+			UpdateR([]tab.Column{d.Body}, []tab.Column{d.Version}, d.ID, d.Version)),
+		q("where bumps", `UPDATE "docs" SET "body" = $1, "version" = "version" + 1 WHERE "id" > $2`, d.Body, d.ID)(
+			UpdateWhere([]tab.Column{d.Body}, pred.Gt(d.ID))),
+		q("timestamp", `UPDATE "notes" SET "id" = $1, "updated_at" = CURRENT_TIMESTAMP WHERE (("id" = $2) AND "updated_at" = $3)`, noteID(2), noteID(1), at)(
+			Update([]tab.Column{noteID(2), at}, noteID(1))),
+	})
+
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"no version", second(Update([]tab.Column{d.Body}, d.ID))},
+		{"unset version", second(Update([]tab.Column{d.Body, docVersion(0)}, d.ID))},
+		{"unset version condition", second(Update([]tab.Column{d.Body}, d.ID, docVersion(0)))},
+		{"unset timestamp", second(Update([]tab.Column{noteID(2), noteUpdatedAt{}}, noteID(1)))},
+		{"only the version", second(Update([]tab.Column{d.Version}, d.ID))},
+	}
+	for _, c := range cases {
+		if _, ok := c.err.(tab.QueryGenerationError); !ok {
+			t.Errorf("%s: got %v, want a QueryGenerationError", c.name, c.err)
+		}
+	}
+}
+
+func TestStaleRow(t *testing.T) {
+	d := docs{ID: 1, Body: "b", Version: 3}
+	q, err := Update([]tab.Column{d.Body}, d.ID, d.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Exec(context.Background(), open(&fakeDB{}), q)
+	if stale, ok := err.(ErrStaleRow); !ok || len(stale.Key) != 2 || stale.Table.Name() != "docs" {
+		t.Errorf("exec: got %v, want ErrStaleRow", err)
+	}
+	if _, err := Exec(context.Background(), open(&fakeDB{affected: 1}), q); err != nil {
+		t.Errorf("exec: got %v for an updated row", err)
+	}
+
+	var got []docs
+	err = UpdateReturning(context.Background(), open(&fakeDB{cols: []string{"version"}}), &got, []tab.Column{d.Body}, []tab.Column{d.Version}, d.ID, d.Version)
+	if _, ok := err.(ErrStaleRow); !ok {
+		t.Errorf("returning: got %v, want ErrStaleRow", err)
+	}
+
+	// an UpdateWhere isn't checked
+	q, _ = UpdateWhere([]tab.Column{d.Body}, pred.Gt(d.ID))
+	if _, err := Exec(context.Background(), open(&fakeDB{}), q); err != nil {
+		t.Errorf("where: got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package dialect
+
+import (
+	"strconv"
+	"strings"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// Postgres is the PostgreSQL dialect, with $# placeholders
+var Postgres tab.Dialect = postgres{}
+
+// MySQL is the MySQL dialect, with ? placeholders
+var MySQL tab.Dialect = mysql{}
+
+// SQLite is the SQLite dialect, with ? placeholders
+var SQLite tab.Dialect = sqlite{}
+
+// Question wraps d to bind arguments with ? placeholders,
+// for drivers or sqlx.Rebind calls expecting them
+func Question(d tab.Dialect) tab.Dialect {
+	return question{d}
+}
+
+// Format adapts a Dialect to sqrl.PlaceholderFormat,
+// replacing each ? with the dialect's placeholder and ?? with a literal ?, in every dialect
+type Format struct {
+	tab.Dialect
+}
+
+// ReplacePlaceholders implements sqrl.PlaceholderFormat
+func (f Format) ReplacePlaceholders(sql string) (string, error) {
+	buf := &strings.Builder{}
+	n := 0
+	for {
+		p := strings.Index(sql, "?")
+		if p == -1 {
+			break
+		}
+		if len(sql[p:]) > 1 && sql[p:p+2] == "??" {
+			buf.WriteString(sql[:p])
+			buf.WriteString("?")
+			sql = sql[p+2:]
+			continue
+		}
+		n++
+		buf.WriteString(sql[:p])
+		buf.WriteString(f.Placeholder(n))
+		sql = sql[p+1:]
+	}
+	buf.WriteString(sql)
+	return buf.String(), nil
+}
+
+type postgres struct{}
+
+func (postgres) Name() string                  { return "postgres" }
+func (postgres) QuoteIdent(name string) string { return quote(name, `"`) }
+func (postgres) QuoteValue(v string) string    { return quote(v, `'`) }
+func (postgres) Placeholder(n int) string      { return "$" + strconv.Itoa(n) }
+func (postgres) Returning() bool               { return true }
+func (postgres) Upsert(c tab.Conflict) (string, []interface{}, error) {
+	return onConflict(c)
+}
+func (postgres) Bool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+func (postgres) Limit(limit, offset uint64) string {
+	return limitOffset(limit, offset, "")
+}
+func (postgres) OrderBy(col string, desc bool, nulls tab.Nulls) string {
+	return orderBy(col, desc, nulls)
+}
+
+type mysql struct{}
+
+func (mysql) Name() string                  { return "mysql" }
+func (mysql) QuoteIdent(name string) string { return quote(name, "`") }
+func (mysql) QuoteValue(v string) string {
+	return quote(strings.Replace(v, `\`, `\\`, -1), `'`)
+}
+func (mysql) Placeholder(n int) string { return "?" }
+func (mysql) Returning() bool          { return false }
+func (mysql) Upsert(c tab.Conflict) (string, []interface{}, error) {
+	if len(c.Constraint) > 0 || c.TargetWhere != nil || c.UpdateWhere != nil {
+		return "", nil, tab.QueryGenerationError{Message: "mysql upsert resolves any unique key, without conditions"}
+	}
+	if len(c.Update) < 1 {
+		if len(c.Target) < 1 {
+			return "", nil, tab.QueryGenerationError{Message: "mysql upsert needs a conflict target or update columns"}
+		}
+		// no DO NOTHING in mysql, a self assignment leaves the row untouched
+		return "ON DUPLICATE KEY UPDATE " + c.Target[0] + " = " + c.Target[0], nil, nil
+	}
+	upd := []string{}
+	for _, col := range c.Update {
+		upd = append(upd, col+" = VALUES("+col+")")
+	}
+	return "ON DUPLICATE KEY UPDATE " + strings.Join(upd, ", "), nil, nil
+}
+func (mysql) Bool(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+func (mysql) Limit(limit, offset uint64) string {
+	// mysql can't OFFSET without a LIMIT, use the largest one
+	return limitOffset(limit, offset, "18446744073709551615")
+}
+func (mysql) OrderBy(col string, desc bool, nulls tab.Nulls) string {
+	// no NULLS FIRST/LAST in mysql, sort on IS NULL first, true after false
+	switch nulls {
+	case tab.NullsFirst:
+		return col + " IS NULL DESC, " + orderBy(col, desc, tab.NullsDefault)
+	case tab.NullsLast:
+		return col + " IS NULL ASC, " + orderBy(col, desc, tab.NullsDefault)
+	}
+	return orderBy(col, desc, nulls)
+}
+
+type sqlite struct{}
+
+func (sqlite) Name() string                  { return "sqlite3" }
+func (sqlite) QuoteIdent(name string) string { return quote(name, `"`) }
+func (sqlite) QuoteValue(v string) string    { return quote(v, `'`) }
+func (sqlite) Placeholder(n int) string      { return "?" }
+func (sqlite) Returning() bool               { return true }
+func (sqlite) Upsert(c tab.Conflict) (string, []interface{}, error) {
+	if len(c.Constraint) > 0 {
+		if len(c.Target) < 1 {
+			return "", nil, tab.QueryGenerationError{Message: "sqlite upsert can't target a constraint by name"}
+		}
+		// sqlite only resolves conflicts on columns
+		c.Constraint = ""
+	}
+	return onConflict(c)
+}
+func (sqlite) Bool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+func (sqlite) Limit(limit, offset uint64) string {
+	// sqlite can't OFFSET without a LIMIT, -1 is unbounded
+	return limitOffset(limit, offset, "-1")
+}
+func (sqlite) OrderBy(col string, desc bool, nulls tab.Nulls) string {
+	return orderBy(col, desc, nulls)
+}
+
+type question struct {
+	tab.Dialect
+}
+
+func (question) Placeholder(n int) string { return "?" }
+
+func quote(s, q string) string {
+	return q + strings.Replace(s, q, q+q, -1) + q
+}
+
+// onConflict renders the ON CONFLICT clause shared by postgres and sqlite
+func onConflict(c tab.Conflict) (string, []interface{}, error) {
+	var args []interface{}
+	target := ""
+	if len(c.Constraint) > 0 {
+		target = " ON CONSTRAINT " + c.Constraint
+	} else if len(c.Target) > 0 {
+		target = " (" + strings.Join(c.Target, ",") + ")"
+		if c.TargetWhere != nil {
+			target += " WHERE " + c.TargetWhere.SQL()
+			args = append(args, c.TargetWhere.Args()...)
+		}
+	} else if c.TargetWhere != nil {
+		return "", nil, tab.QueryGenerationError{Message: "ON CONFLICT WHERE requires conflict columns"}
+	}
+	if len(c.Update) < 1 {
+		if c.UpdateWhere != nil {
+			return "", nil, tab.QueryGenerationError{Message: "ON CONFLICT DO NOTHING can't have an update condition"}
+		}
+		return "ON CONFLICT" + target + " DO NOTHING", args, nil
+	}
+	if len(target) < 1 {
+		return "", nil, tab.QueryGenerationError{Message: "ON CONFLICT DO UPDATE requires a conflict target"}
+	}
+	upd := []string{}
+	for _, col := range c.Update {
+		upd = append(upd, col+" = EXCLUDED."+col)
+	}
+	s := "ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(upd, ", ")
+	if c.UpdateWhere != nil {
+		s += " WHERE " + c.UpdateWhere.SQL()
+		args = append(args, c.UpdateWhere.Args()...)
+	}
+	return s, args, nil
+}
+
+func orderBy(col string, desc bool, nulls tab.Nulls) string {
+	s := col + " ASC"
+	if desc {
+		s = col + " DESC"
+	}
+	switch nulls {
+	case tab.NullsFirst:
+		s += " NULLS FIRST"
+	case tab.NullsLast:
+		s += " NULLS LAST"
+	}
+	return s
+}
+
+func limitOffset(limit, offset uint64, unbounded string) string {
+	s := []string{}
+	if limit > 0 {
+		s = append(s, "LIMIT "+strconv.FormatUint(limit, 10))
+	} else if offset > 0 && len(unbounded) > 0 {
+		s = append(s, "LIMIT "+unbounded)
+	}
+	if offset > 0 {
+		s = append(s, "OFFSET "+strconv.FormatUint(offset, 10))
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,144 @@
+package dialect
+
+import (
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+func TestFormat(t *testing.T) {
+	sql := "SELECT * FROM t WHERE a = ? AND b ?? 'k' AND c IN (?,?)"
+	cases := []struct {
+		d    tab.Dialect
+		want string
+	}{
+		{Postgres, "SELECT * FROM t WHERE a = $1 AND b ? 'k' AND c IN ($2,$3)"},
+		{MySQL, "SELECT * FROM t WHERE a = ? AND b ? 'k' AND c IN (?,?)"},
+		{SQLite, "SELECT * FROM t WHERE a = ? AND b ? 'k' AND c IN (?,?)"},
+		{Question(Postgres), "SELECT * FROM t WHERE a = ? AND b ? 'k' AND c IN (?,?)"},
+	}
+	for _, c := range cases {
+		got, err := Format{c.d}.ReplacePlaceholders(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != c.want {
+			t.Errorf("%s: got %q, want %q", c.d.Name(), got, c.want)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	cases := []struct {
+		d            tab.Dialect
+		ident, value string
+	}{
+		{Postgres, `"a""b"`, `'it''s\'`},
+		{MySQL, "`a\"b`", `'it''s\\'`},
+		{SQLite, `"a""b"`, `'it''s\'`},
+	}
+	for _, c := range cases {
+		if got := c.d.QuoteIdent(`a"b`); got != c.ident {
+			t.Errorf("%s: QuoteIdent got %s, want %s", c.d.Name(), got, c.ident)
+		}
+		if got := c.d.QuoteValue(`it's\`); got != c.value {
+			t.Errorf("%s: QuoteValue got %s, want %s", c.d.Name(), got, c.value)
+		}
+	}
+	if got := MySQL.QuoteIdent("a`b"); got != "`a``b`" {
+		t.Errorf("mysql: QuoteIdent got %s", got)
+	}
+}
+
+func TestPlaceholderBoolReturning(t *testing.T) {
+	cases := []struct {
+		d         tab.Dialect
+		p2        string
+		yes, no   string
+		returning bool
+	}{
+		{Postgres, "$2", "TRUE", "FALSE", true},
+		{MySQL, "?", "TRUE", "FALSE", false},
+		{SQLite, "?", "1", "0", true},
+		{Question(Postgres), "?", "TRUE", "FALSE", true},
+	}
+	for _, c := range cases {
+		if got := c.d.Placeholder(2); got != c.p2 {
+			t.Errorf("%s: Placeholder got %s, want %s", c.d.Name(), got, c.p2)
+		}
+		if c.d.Bool(true) != c.yes || c.d.Bool(false) != c.no {
+			t.Errorf("%s: Bool got %s %s", c.d.Name(), c.d.Bool(true), c.d.Bool(false))
+		}
+		if c.d.Returning() != c.returning {
+			t.Errorf("%s: Returning got %v", c.d.Name(), c.d.Returning())
+		}
+	}
+}
+
+func TestLimit(t *testing.T) {
+	cases := []struct {
+		d             tab.Dialect
+		limit, offset uint64
+		want          string
+	}{
+		{Postgres, 0, 0, ""},
+		{Postgres, 10, 0, "LIMIT 10"},
+		{Postgres, 10, 20, "LIMIT 10 OFFSET 20"},
+		{Postgres, 0, 20, "OFFSET 20"},
+		{MySQL, 0, 20, "LIMIT 18446744073709551615 OFFSET 20"},
+		{SQLite, 0, 20, "LIMIT -1 OFFSET 20"},
+		{SQLite, 5, 0, "LIMIT 5"},
+	}
+	for _, c := range cases {
+		if got := c.d.Limit(c.limit, c.offset); got != c.want {
+			t.Errorf("%s %d %d: got %q, want %q", c.d.Name(), c.limit, c.offset, got, c.want)
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	where := tab.Sq{S: `"n" > ?`, A: []interface{}{1}}
+	cases := []struct {
+		d    tab.Dialect
+		c    tab.Conflict
+		want string
+		args int
+	}{
+		{Postgres, tab.Conflict{Target: []string{`"a"`, `"b"`}, Update: []string{`"c"`}}, `ON CONFLICT ("a","b") DO UPDATE SET "c" = EXCLUDED."c"`, 0},
+		{Postgres, tab.Conflict{Constraint: `"k"`, Target: []string{`"a"`}}, `ON CONFLICT ON CONSTRAINT "k" DO NOTHING`, 0},
+		{Postgres, tab.Conflict{Target: []string{`"a"`}, TargetWhere: where, Update: []string{`"c"`}, UpdateWhere: where},
+			`ON CONFLICT ("a") WHERE "n" > ? DO UPDATE SET "c" = EXCLUDED."c" WHERE "n" > ?`, 2},
+		{SQLite, tab.Conflict{Constraint: `"k"`, Target: []string{`"a"`}, Update: []string{`"c"`}}, `ON CONFLICT ("a") DO UPDATE SET "c" = EXCLUDED."c"`, 0},
+		{SQLite, tab.Conflict{}, `ON CONFLICT DO NOTHING`, 0},
+		{MySQL, tab.Conflict{Target: []string{"`a`"}, Update: []string{"`b`", "`c`"}}, "ON DUPLICATE KEY UPDATE `b` = VALUES(`b`), `c` = VALUES(`c`)", 0},
+		{MySQL, tab.Conflict{Target: []string{"`a`"}}, "ON DUPLICATE KEY UPDATE `a` = `a`", 0},
+	}
+	for _, c := range cases {
+		got, args, err := c.d.Upsert(c.c)
+		if err != nil {
+			t.Errorf("%s %+v: %v", c.d.Name(), c.c, err)
+			continue
+		}
+		if got != c.want || len(args) != c.args {
+			t.Errorf("%s: got %q with %d args, want %q with %d", c.d.Name(), got, len(args), c.want, c.args)
+		}
+	}
+
+	errs := []struct {
+		d tab.Dialect
+		c tab.Conflict
+	}{
+		{Postgres, tab.Conflict{Update: []string{`"c"`}}},
+		{Postgres, tab.Conflict{TargetWhere: where}},
+		{Postgres, tab.Conflict{Target: []string{`"a"`}, UpdateWhere: where}},
+		{SQLite, tab.Conflict{Constraint: `"k"`}},
+		{MySQL, tab.Conflict{Constraint: "`k`", Target: []string{"`a`"}}},
+		{MySQL, tab.Conflict{Target: []string{"`a`"}, Update: []string{"`b`"}, UpdateWhere: where}},
+		{MySQL, tab.Conflict{}},
+	}
+	for _, c := range errs {
+		if _, _, err := c.d.Upsert(c.c); err == nil {
+			t.Errorf("%s %+v: got no error", c.d.Name(), c.c)
+		}
+	}
+}
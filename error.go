@@ -1,6 +1,11 @@
 package tabua
 
 import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+
 	pq "github.com/lib/pq"
 )
 
@@ -23,13 +28,192 @@ func (cve QueryGenerationError) Error() string {
 	return "Error generation query: " + cve.Message
 }
 
-func handleError(err error) error {
-	if driverErr, ok := err.(*pq.Error); ok { // Now the error number is accessible directly
-		if driverErr.Code == "1045" {
-			// Handle the permission-denied error
+// UnconditionalError is returned for an UPDATE or DELETE without conditions, which would change every row of Table
+type UnconditionalError struct {
+	Statement string
+	Table     string
+}
+
+func (e UnconditionalError) Error() string {
+	return e.Statement + " of " + e.Table + " has no conditions, use AllRows to change every row"
+}
+
+// ConstraintViolation describes a write refused by a constraint of Table
+// Table, Columns and Constraint are resolved from registered or given tables, and are nil when not found
+type ConstraintViolation struct {
+	Table      Table
+	Columns    []Column
+	Constraint Constrainer
+	// Err is the error returned by the driver
+	Err  *pq.Error
+	kind string
+}
+
+func (e ConstraintViolation) Error() string {
+	names := []string{}
+	for _, c := range e.Columns {
+		names = append(names, c.Name())
+	}
+	table := e.Err.Table
+	if e.Table != nil {
+		table = e.Table.Name()
+	}
+	return e.kind + " on " + table + " (" + strings.Join(names, ", ") + "): " + e.Err.Message
+}
+
+// Unwrap returns the driver error
+func (e ConstraintViolation) Unwrap() error {
+	return e.Err
+}
+
+// UniqueViolation is returned for a duplicate value of a unique or primary key, the Columns of the key
+type UniqueViolation struct{ ConstraintViolation }
+
+// ForeignKeyViolation is returned for a value missing from the referenced table, the Columns referencing it
+type ForeignKeyViolation struct{ ConstraintViolation }
+
+// NotNullViolation is returned for a NULL value of a non null column
+type NotNullViolation struct{ ConstraintViolation }
+
+// CheckViolation is returned for a row failing a check constraint
+type CheckViolation struct{ ConstraintViolation }
+
+// ExclusionViolation is returned for a row conflicting with another in an exclusion constraint
+type ExclusionViolation struct{ ConstraintViolation }
+
+var (
+	tablesMu sync.RWMutex
+	tables   = map[string]Table{}
+)
+
+// Register makes tables known to Classify, generated packages register their table when
+// generated with generate.Generator.Register. A table with the schema and name of one
+// registered before replaces it silently
+func Register(ts ...Table) {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	for _, t := range ts {
+		tables[tableKey(t)] = t
+	}
+}
+
+func tableKey(t Table) string {
+	if s, ok := t.(Schemer); ok && len(s.Schema()) > 0 {
+		return s.Schema() + "." + t.Name()
+	}
+	return t.Name()
+}
+
+// Classify turns a constraint violation reported by the driver into a typed error, looking
+// its table up in ts and then among registered tables. Other errors are returned as they are
+func Classify(err error, ts ...Table) error {
+	var pqErr *pq.Error
+	if err == nil || !errors.As(err, &pqErr) {
+		return err
+	}
+	v := ConstraintViolation{Err: pqErr}
+	v.Table = lookupTable(pqErr, ts)
+	if v.Table != nil && len(pqErr.Constraint) > 0 {
+		v.Constraint, _ = ConstraintNamed(v.Table, pqErr.Constraint)
+	}
+
+	switch pqErr.Code {
+	case "23505":
+		v.kind = "unique violation"
+		v.Columns = v.columns(keyColumns(v.Constraint))
+		return UniqueViolation{v}
+	case "23503":
+		v.kind = "foreign key violation"
+		if fk, ok := v.Constraint.(FKConstrainer); ok {
+			v.Columns = fk.Key().From
+		} else {
+			v.Columns = v.columns(nil)
+		}
+		return ForeignKeyViolation{v}
+	case "23502":
+		v.kind = "not null violation"
+		if c, ok := v.column(pqErr.Column); ok {
+			v.Columns = []Column{c}
 		}
-	} /*else if driverErr, ok := err.(*sql.ErrNoRows); ok { // Now the error number is accessible directly
-		// not found
-	}*/
+		return NotNullViolation{v}
+	case "23514":
+		v.kind = "check violation"
+		if cc, ok := v.Constraint.(CheckConstrainer); ok {
+			v.Columns = cc.Columns()
+		}
+		return CheckViolation{v}
+	case "23P01":
+		v.kind = "exclusion violation"
+		return ExclusionViolation{v}
+	}
 	return err
 }
+
+func lookupTable(e *pq.Error, ts []Table) Table {
+	if len(e.Table) < 1 {
+		return nil
+	}
+	match := func(t Table) bool {
+		if t.Name() != e.Table {
+			return false
+		}
+		s, ok := t.(Schemer)
+		return !ok || len(e.Schema) < 1 || s.Schema() == e.Schema
+	}
+	for _, t := range ts {
+		if match(t) {
+			return t
+		}
+	}
+	tablesMu.RLock()
+	defer tablesMu.RUnlock()
+	if t, ok := tables[e.Schema+"."+e.Table]; ok {
+		return t
+	}
+	if t, ok := tables[e.Table]; ok && match(t) {
+		return t
+	}
+	return nil
+}
+
+func keyColumns(c Constrainer) []Column {
+	switch c := c.(type) {
+	case PKConstrainer:
+		return c.Keys()
+	case UniqueConstrainer:
+		return c.Uniques()
+	}
+	return nil
+}
+
+// detailKey matches the columns of a key in an error detail, "Key (a, b)=(1, 2) already exists."
+var detailKey = regexp.MustCompile(`^Key \(([^)]*)\)=`)
+
+// columns returns cols, or the table columns named in the error detail when the constraint wasn't found
+func (v ConstraintViolation) columns(cols []Column) []Column {
+	if len(cols) > 0 {
+		return cols
+	}
+	m := detailKey.FindStringSubmatch(v.Err.Detail)
+	if len(m) < 2 {
+		return nil
+	}
+	for _, name := range strings.Split(m[1], ",") {
+		if c, ok := v.column(strings.Trim(strings.TrimSpace(name), `"`)); ok {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+func (v ConstraintViolation) column(name string) (Column, bool) {
+	if v.Table == nil || len(name) < 1 {
+		return nil, false
+	}
+	for _, c := range v.Table.Columns() {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,198 @@
+package tabua
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	pq "github.com/lib/pq"
+)
+
+// accounts is a table of the bank schema with a constraint of each kind
+type accounts struct {
+	ID      accountID
+	Email   accountEmail
+	Owner   accountOwner
+	Balance accountBalance
+}
+
+func (accounts) Name() string              { return "accounts" }
+func (accounts) Schema() string            { return "bank" }
+func (accounts) Constraints() []Constraint { return nil }
+func (a accounts) Columns() []Column       { return []Column{a.ID, a.Email, a.Owner, a.Balance} }
+func (accounts) Constrainers() []Constrainer {
+	return []Constrainer{accountsPK{}, accountsEmailKey{}, accountsOwnerFK{}, accountsBalanceCheck{}}
+}
+
+type accountID int64
+
+func (accountID) Name() string    { return "id" }
+func (accountID) Table() Table    { return accounts{} }
+func (accountID) SQLType() string { return "int8,bigint" }
+func (accountID) NonNull() bool   { return true }
+
+type accountEmail string
+
+func (accountEmail) Name() string    { return "email" }
+func (accountEmail) Table() Table    { return accounts{} }
+func (accountEmail) SQLType() string { return "text,text" }
+func (accountEmail) NonNull() bool   { return true }
+
+type accountOwner int64
+
+func (accountOwner) Name() string    { return "owner_id" }
+func (accountOwner) Table() Table    { return accounts{} }
+func (accountOwner) SQLType() string { return "int8,bigint" }
+func (accountOwner) NonNull() bool   { return true }
+
+type accountBalance float64
+
+func (accountBalance) Name() string    { return "balance" }
+func (accountBalance) Table() Table    { return accounts{} }
+func (accountBalance) SQLType() string { return "float8,double precision" }
+func (accountBalance) NonNull() bool   { return true }
+
+type accountsPK struct{}
+
+func (accountsPK) Name() string         { return "accounts_pkey" }
+func (accountsPK) Type() ConstraintType { return ConstraintPK }
+func (accountsPK) Definition() string   { return "PRIMARY KEY (id)" }
+func (accountsPK) Keys() []Column       { return []Column{accountID(0)} }
+
+type accountsEmailKey struct{}
+
+func (accountsEmailKey) Name() string         { return "accounts_email_key" }
+func (accountsEmailKey) Type() ConstraintType { return ConstraintUnique }
+func (accountsEmailKey) Definition() string   { return "UNIQUE (email)" }
+func (accountsEmailKey) Uniques() []Column    { return []Column{accountEmail("")} }
+
+type accountsOwnerFK struct{}
+
+func (accountsOwnerFK) Name() string         { return "accounts_owner_id_fkey" }
+func (accountsOwnerFK) Type() ConstraintType { return ConstraintFK }
+func (accountsOwnerFK) Definition() string   { return "FOREIGN KEY (owner_id) REFERENCES owners(id)" }
+func (accountsOwnerFK) Key() FK              { return FK{From: []Column{accountOwner(0)}, To: []Column{ownerID(0)}} }
+
+type accountsBalanceCheck struct{}
+
+func (accountsBalanceCheck) Name() string         { return "accounts_balance_check" }
+func (accountsBalanceCheck) Type() ConstraintType { return ConstraintCheck }
+func (accountsBalanceCheck) Definition() string   { return "CHECK ((balance >= (0)::double precision))" }
+func (accountsBalanceCheck) Columns() []Column    { return []Column{accountBalance(0)} }
+
+// owners has no constraints
+type owners struct{}
+
+func (owners) Name() string              { return "owners" }
+func (owners) Schema() string            { return "bank" }
+func (owners) Constraints() []Constraint { return nil }
+func (owners) Columns() []Column         { return []Column{ownerID(0)} }
+
+type ownerID int64
+
+func (ownerID) Name() string    { return "id" }
+func (ownerID) Table() Table    { return owners{} }
+func (ownerID) SQLType() string { return "int8,bigint" }
+func (ownerID) NonNull() bool   { return true }
+
+func TestClassify(t *testing.T) {
+	a := accounts{}
+	cases := []struct {
+		name       string
+		err        *pq.Error
+		ts         []Table
+		kind       interface{}
+		table      Table
+		constraint Constrainer
+		cols       []Column
+	}{
+		{"unique", &pq.Error{Code: "23505", Schema: "bank", Table: "accounts", Constraint: "accounts_email_key", Detail: "Key (email)=(a@b.c) already exists."},
+			[]Table{a}, UniqueViolation{}, a, accountsEmailKey{}, []Column{accountEmail("")}},
+		{"primary key", &pq.Error{Code: "23505", Schema: "bank", Table: "accounts", Constraint: "accounts_pkey"},
+			[]Table{owners{}, a}, UniqueViolation{}, a, accountsPK{}, []Column{accountID(0)}},
+		{"unique of an unknown constraint", &pq.Error{Code: "23505", Schema: "bank", Table: "accounts", Constraint: "accounts_lower_email_idx", Detail: `Key (email, "owner_id")=(a@b.c, 1) already exists.`},
+			[]Table{a}, UniqueViolation{}, a, nil, []Column{accountEmail(""), accountOwner(0)}},
+		{"unique of an expression", &pq.Error{Code: "23505", Schema: "bank", Table: "accounts", Constraint: "accounts_lower_idx", Detail: "Key (lower(email))=(a@b.c) already exists."},
+			[]Table{a}, UniqueViolation{}, a, nil, nil},
+		{"unique without a detail", &pq.Error{Code: "23505", Schema: "bank", Table: "accounts", Constraint: "accounts_lower_idx"},
+			[]Table{a}, UniqueViolation{}, a, nil, nil},
+		{"foreign key", &pq.Error{Code: "23503", Schema: "bank", Table: "accounts", Constraint: "accounts_owner_id_fkey", Detail: `Key (owner_id)=(9) is not present in table "owners".`},
+			[]Table{a}, ForeignKeyViolation{}, a, accountsOwnerFK{}, []Column{accountOwner(0)}},
+		{"foreign key of an unknown constraint", &pq.Error{Code: "23503", Schema: "bank", Table: "accounts", Constraint: "accounts_x_fkey", Detail: `Key (owner_id)=(9) is not present in table "owners".`},
+			[]Table{a}, ForeignKeyViolation{}, a, nil, []Column{accountOwner(0)}},
+		{"not null", &pq.Error{Code: "23502", Schema: "bank", Table: "accounts", Column: "email"},
+			[]Table{a}, NotNullViolation{}, a, nil, []Column{accountEmail("")}},
+		{"check", &pq.Error{Code: "23514", Schema: "bank", Table: "accounts", Constraint: "accounts_balance_check"},
+			[]Table{a}, CheckViolation{}, a, accountsBalanceCheck{}, []Column{accountBalance(0)}},
+		{"exclusion", &pq.Error{Code: "23P01", Schema: "bank", Table: "accounts", Constraint: "accounts_period_excl"},
+			[]Table{a}, ExclusionViolation{}, a, nil, nil},
+		{"unknown table", &pq.Error{Code: "23505", Schema: "bank", Table: "ledgers", Constraint: "ledgers_pkey", Detail: "Key (id)=(1) already exists."},
+			[]Table{a}, UniqueViolation{}, nil, nil, nil},
+		{"other schema", &pq.Error{Code: "23505", Schema: "audit", Table: "accounts", Constraint: "accounts_pkey", Detail: "Key (id)=(1) already exists."},
+			[]Table{a}, UniqueViolation{}, nil, nil, nil},
+		{"without a schema", &pq.Error{Code: "23505", Table: "accounts", Constraint: "accounts_pkey"},
+			[]Table{a}, UniqueViolation{}, a, accountsPK{}, []Column{accountID(0)}},
+	}
+	for _, c := range cases {
+		err := Classify(fmt.Errorf("inserting: %w", c.err), c.ts...)
+		if reflect.TypeOf(err) != reflect.TypeOf(c.kind) {
+			t.Errorf("%s: got %T, want %T", c.name, err, c.kind)
+			continue
+		}
+		var v ConstraintViolation
+		switch e := err.(type) {
+		case UniqueViolation:
+			v = e.ConstraintViolation
+		case ForeignKeyViolation:
+			v = e.ConstraintViolation
+		case NotNullViolation:
+			v = e.ConstraintViolation
+		case CheckViolation:
+			v = e.ConstraintViolation
+		case ExclusionViolation:
+			v = e.ConstraintViolation
+		}
+		if v.Table != c.table || v.Constraint != c.constraint || !reflect.DeepEqual(v.Columns, c.cols) {
+			t.Errorf("%s: got %v %v %v, want %v %v %v", c.name, v.Table, v.Constraint, v.Columns, c.table, c.constraint, c.cols)
+		}
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr != c.err {
+			t.Errorf("%s: unwrapped %v, want the driver error", c.name, pqErr)
+		}
+	}
+}
+
+func TestClassifyOthers(t *testing.T) {
+	other := errors.New("failed")
+	undefined := &pq.Error{Code: "42P01", Table: "accounts"}
+	for _, err := range []error{nil, other, undefined} {
+		if got := Classify(err, accounts{}); got != err {
+			t.Errorf("got %v, want %v as it is", got, err)
+		}
+	}
+}
+
+func TestClassifyRegistered(t *testing.T) {
+	err := &pq.Error{Code: "23505", Schema: "bank", Table: "accounts", Constraint: "accounts_email_key", Message: `duplicate key value violates unique constraint "accounts_email_key"`}
+	if u, ok := Classify(err).(UniqueViolation); !ok || u.Table != nil {
+		t.Fatalf("got %#v before registering", u)
+	}
+	Register(owners{}, accounts{})
+	u, ok := Classify(err).(UniqueViolation)
+	if !ok || u.Table != (accounts{}) || !reflect.DeepEqual(u.Columns, []Column{accountEmail("")}) {
+		t.Errorf("got %#v, want the registered table", u)
+	}
+	want := `unique violation on accounts (email): duplicate key value violates unique constraint "accounts_email_key"`
+	if u.Error() != want {
+		t.Errorf("got %q, want %q", u.Error(), want)
+	}
+	if !errors.As(Classify(err), &UniqueViolation{}) {
+		t.Error("not a UniqueViolation for errors.As")
+	}
+
+	// the given tables come first
+	if u, _ := Classify(err, owners{}).(UniqueViolation); u.Table != (accounts{}) {
+		t.Errorf("got %v, want the registered table", u.Table)
+	}
+}
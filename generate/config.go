@@ -0,0 +1,82 @@
+package generate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pindamonhangaba/tabua/generate/types"
+)
+
+// Config customizes the Go types picked for columns, types are written
+// as "import/path.Name", e.g. "github.com/google/uuid.UUID"
+type Config struct {
+	// Types maps udt names to Go types
+	Types map[string]TypeConfig `json:"types"`
+	// Columns maps table.column or schema.table.column to a Go type
+	Columns map[string]string `json:"columns"`
+	// SoftDelete maps table or schema.table to the column marking its rows deleted
+	SoftDelete map[string]string `json:"soft_delete"`
+	// SoftDeleteColumn soft deletes every table with a nullable column of this name
+	SoftDeleteColumn string `json:"soft_delete_column"`
+	// Version maps table or schema.table to its optimistic locking version column
+	Version map[string]string `json:"version"`
+	// VersionColumn locks every table with an integer or timestamp column of this name
+	VersionColumn string `json:"version_column"`
+	// Register generates an init registering each table with tabua.Register
+	Register bool `json:"register"`
+}
+
+// TypeConfig holds the Go types of an SQL type
+type TypeConfig struct {
+	Type string `json:"type"`
+	// Null is the type of nullable columns, Type if empty
+	Null string `json:"null"`
+}
+
+// LoadConfig reads a JSON Config from path
+func LoadConfig(path string) (c Config, err error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// Apply registers the configured types and sets the column overrides of g
+func (c Config) Apply(g *Generator) {
+	for udt, tc := range c.Types {
+		null := tc.Null
+		if len(null) < 1 {
+			null = tc.Type
+		}
+		types.Register(udt, types.ParseGoType(tc.Type), types.ParseGoType(null))
+	}
+	if len(c.Columns) > 0 && g.Columns == nil {
+		g.Columns = map[string]types.GoType{}
+	}
+	for col, typ := range c.Columns {
+		g.Columns[col] = types.ParseGoType(typ)
+	}
+	if len(c.SoftDelete) > 0 && g.SoftDelete == nil {
+		g.SoftDelete = map[string]string{}
+	}
+	for t, col := range c.SoftDelete {
+		g.SoftDelete[t] = col
+	}
+	if len(c.SoftDeleteColumn) > 0 {
+		g.SoftDeleteColumn = c.SoftDeleteColumn
+	}
+	if len(c.Version) > 0 && g.Version == nil {
+		g.Version = map[string]string{}
+	}
+	for t, col := range c.Version {
+		g.Version[t] = col
+	}
+	if len(c.VersionColumn) > 0 {
+		g.VersionColumn = c.VersionColumn
+	}
+	if c.Register {
+		g.Register = true
+	}
+}
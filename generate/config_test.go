@@ -0,0 +1,59 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pindamonhangaba/tabua/generate/types"
+	"github.com/pindamonhangaba/tabua/reverse"
+)
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.json")
+	err := os.WriteFile(path, []byte(`{
+		"types": {"test_ltree": {"type": "example.com/ltree.Path", "null": "example.com/ltree.NullPath"}},
+		"columns": {"docs.body": "example.com/doc.Body"},
+		"soft_delete": {"docs": "removed_at"},
+		"version_column": "rev",
+		"register": true
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Generator{}
+	c.Apply(g)
+
+	if got, _ := types.Lookup(types.SQLType{Name: "test_ltree"}, false); got.String() != "ltree.NullPath" {
+		t.Errorf("registered null type: got %s", got)
+	}
+	if g.Columns["docs.body"].String() != "doc.Body" || g.SoftDelete["docs"] != "removed_at" || g.VersionColumn != "rev" || !g.Register {
+		t.Errorf("got generator %+v", g)
+	}
+
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loaded a missing file")
+	}
+}
+
+func TestColumnOverride(t *testing.T) {
+	docs := reverse.Table{Name: "docs", Schema: "app", Columns: []reverse.Column{
+		{Name: "id", Position: 1, UDTName: "uuid", DataType: "uuid", NonNull: true},
+		{Name: "body", Position: 2, UDTName: "text", DataType: "text", NonNull: true},
+	}}
+	g := &Generator{Columns: map[string]types.GoType{
+		"app.docs.id": types.ParseGoType("github.com/google/uuid.UUID"),
+		"docs.body":   types.ParseGoType("[]string"),
+	}}
+	code := render(t, g, docs)
+	contains(t, code,
+		"type ID uuid.UUID",
+		"func (c ID) Value() (driver.Value, error) {\n\treturn tabua.ValueOf(uuid.UUID(c))\n}",
+		"func (c *ID) Scan(src interface{}) error {\n\treturn tabua.ScanInto((*uuid.UUID)(c), src)\n}",
+		"type Body []string",
+	)
+}
@@ -1,22 +1,109 @@
 package generate
 
 import (
+	"fmt"
+
 	j "github.com/dave/jennifer/jen"
 	tbu "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/generate/types"
 	"github.com/pindamonhangaba/tabua/reverse"
 )
 
 // Generator generates types for a table
 type Generator struct {
 	PackagePath string
+	// SchemaDirs nests each package in a directory named after its schema,
+	// so tables with the same name in different schemas don't collide
+	SchemaDirs bool
+	// Columns overrides the Go type of columns, keyed by table.column or schema.table.column
+	Columns map[string]types.GoType
+	// SoftDelete maps table or schema.table to the column marking its rows deleted
+	SoftDelete map[string]string
+	// SoftDeleteColumn soft deletes every table with a nullable column of this name
+	SoftDeleteColumn string
+	// Version maps table or schema.table to its optimistic locking version column
+	Version map[string]string
+	// VersionColumn locks every table with an integer or timestamp column of this name
+	VersionColumn string
+	// Register adds an init calling tabua.Register with each table, making it known to
+	// tabua.Classify on import. Off by default, Classify can be given the tables instead
+	Register bool
+	// Warnings lists the columns Run couldn't map to a Go type
+	Warnings []string
+}
+
+// columnType returns the Go type of c, from Columns or the types registry
+func (g *Generator) columnType(t reverse.Table, c reverse.Column) (types.GoType, bool) {
+	if gt, ok := g.Columns[t.Schema+"."+t.Name+"."+c.Name]; ok {
+		return gt, true
+	}
+	if gt, ok := g.Columns[t.Name+"."+c.Name]; ok {
+		return gt, true
+	}
+	return reType(c, c.NonNull)
+}
+
+// deletedColumn returns the column marking rows of t deleted, configured or found by SoftDeleteColumn
+func (g *Generator) deletedColumn(t reverse.Table) (reverse.Column, bool) {
+	name, ok := g.SoftDelete[t.Schema+"."+t.Name]
+	if !ok {
+		name, ok = g.SoftDelete[t.Name]
+	}
+	if ok {
+		c, found := t.Column(name)
+		if !found || c.NonNull {
+			g.Warnings = append(g.Warnings, fmt.Sprintf("%s: soft delete column %s is not a nullable column", t.Name, name))
+			return c, false
+		}
+		return c, true
+	}
+	if len(g.SoftDeleteColumn) > 0 {
+		c, found := t.Column(g.SoftDeleteColumn)
+		return c, found && !c.NonNull
+	}
+	return reverse.Column{}, false
+}
+
+// versionColumn returns the version column of t, configured or found by VersionColumn
+func (g *Generator) versionColumn(t reverse.Table) (reverse.Column, bool) {
+	name, ok := g.Version[t.Schema+"."+t.Name]
+	if !ok {
+		name, ok = g.Version[t.Name]
+	}
+	if ok {
+		c, found := t.Column(name)
+		if !found || !isVersion(c) {
+			g.Warnings = append(g.Warnings, fmt.Sprintf("%s: version column %s is not a non null integer or timestamp column", t.Name, name))
+			return c, false
+		}
+		return c, true
+	}
+	if len(g.VersionColumn) > 0 {
+		c, found := t.Column(g.VersionColumn)
+		return c, found && isVersion(c)
+	}
+	return reverse.Column{}, false
 }
 
 // Run generates a jenifer.File and returns the package name
 func (g *Generator) Run(t reverse.Table) (*j.File, string) {
-	return buildTable(t, g.PackagePath), packageFilename(t.Name)
+	return buildTable(t, g), packageFilename(t.Name)
+}
+
+// Dir returns the directory of the table's package, relative to PackagePath
+func (g *Generator) Dir(t reverse.Table) string {
+	return g.dir(t.Schema, t.Name)
+}
+
+func (g *Generator) dir(schema, table string) string {
+	if g.SchemaDirs && len(schema) > 0 {
+		return schema + "/" + packageFilename(table)
+	}
+	return packageFilename(table)
 }
 
-func buildTable(t reverse.Table, pkgPath string) *j.File {
+func buildTable(t reverse.Table, g *Generator) *j.File {
+	t = sorted(t)
 	pkgName := packageFilename(t.Name)
 	tableName := camel(t.Name)
 	file := j.NewFile(pkgName)
@@ -33,6 +120,14 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 	file.Type().Id(tableName).Struct(fields...)
 	file.Line()
 
+	// make the table known to tabua.Classify
+	if g.Register {
+		file.Func().Id("init").Params().Block(
+			j.Qual("github.com/pindamonhangaba/tabua", "Register").Call(j.Id(tableName).Values()),
+		)
+		file.Line()
+	}
+
 	// implement tabua.Namer
 	file.Comment("Name implements the tabua.Namer interface.")
 	file.Func().Params(
@@ -41,6 +136,16 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 		j.Return(j.Lit(t.Name)),
 	)
 
+	// implement tabua.Schemer
+	if len(t.Schema) > 0 {
+		file.Comment("Schema implements the tabua.Schemer interface.")
+		file.Func().Params(
+			j.Id("t").Id(tableName),
+		).Id("Schema").Params().String().Block(
+			j.Return(j.Lit(t.Schema)),
+		)
+	}
+
 	// implement tabua.Table
 	tableColumns := []j.Code{}
 	for _, c := range t.Columns {
@@ -56,16 +161,101 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 	file.Line()
 
 	tableConstraints := []j.Code{}
+	tableConstrainers := []j.Code{}
 	for _, c := range t.Constraints {
-		colName := cstname(c.Name)
-		tableConstraints = append(tableConstraints, j.Id(colName))
+		tableConstraints = append(tableConstraints, j.Lit(c.Name))
+		tableConstrainers = append(tableConstrainers, j.Id(cstname(c.Name)).Values())
 	}
+	// implement tabua.SoftDeleter
+	if c, ok := g.deletedColumn(t); ok {
+		file.Comment("DeletedColumn implements the tabua.SoftDeleter interface.")
+		file.Func().Params(
+			j.Id("t").Id(tableName),
+		).Id("DeletedColumn").Params().Qual("github.com/pindamonhangaba/tabua", "Column").Block(
+			j.Return(j.Id("t").Dot(columnName(t.Name, c.Name))),
+		)
+		file.Line()
+	}
+
+	// implement tabua.Versioned
+	if c, ok := g.versionColumn(t); ok {
+		file.Comment("VersionColumn implements the tabua.Versioned interface.")
+		file.Func().Params(
+			j.Id("t").Id(tableName),
+		).Id("VersionColumn").Params().Qual("github.com/pindamonhangaba/tabua", "Column").Block(
+			j.Return(j.Id("t").Dot(columnName(t.Name, c.Name))),
+		)
+		file.Line()
+	}
+
 	file.Comment("Constraints implements the tabua.Table interface.")
 	file.Func().Params(
 		j.Id("t").Id(tableName),
 	).Id("Constraints").Params().Index().Qual("github.com/pindamonhangaba/tabua", "Constraint").Block(
 		j.Return(j.Index().Qual("github.com/pindamonhangaba/tabua", "Constraint").Values(tableConstraints...)),
 	)
+	file.Comment("Constrainers implements the tabua.Constrained interface.")
+	file.Func().Params(
+		j.Id("t").Id(tableName),
+	).Id("Constrainers").Params().Index().Qual("github.com/pindamonhangaba/tabua", "Constrainer").Block(
+		j.Return(j.Index().Qual("github.com/pindamonhangaba/tabua", "Constrainer").Values(tableConstrainers...)),
+	)
+	file.Line()
+
+	// column values of constraints, a field of the table's zero value
+	// works for any column type, a foreign table is qualified by its package
+	column := func(c reverse.ConstraintColumn) j.Code {
+		if c.Table == t.Name && (len(c.Schema) < 1 || c.Schema == t.Schema) {
+			return j.Id(tableName).Values().Dot(columnName(c.Table, c.Column))
+		}
+		return j.Qual(g.PackagePath+g.dir(c.Schema, c.Table), camel(c.Table)).Values().Dot(columnName(c.Table, c.Column))
+	}
+
+	// implement tabua.Indexer
+	tableIndexes := []j.Code{}
+	for _, idx := range t.Indexes {
+		keys := []j.Code{}
+		for _, k := range idx.Keys {
+			if k.Column != nil {
+				keys = append(keys, j.Values(j.Dict{
+					j.Id("Column"): column(reverse.ConstraintColumn{Schema: t.Schema, Table: t.Name, Column: *k.Column}),
+				}))
+			} else if k.Expression != nil {
+				keys = append(keys, j.Values(j.Dict{
+					j.Id("Expression"): j.Lit(*k.Expression),
+				}))
+			}
+		}
+		include := []j.Code{}
+		for _, c := range idx.Include {
+			include = append(include, column(reverse.ConstraintColumn{Schema: t.Schema, Table: t.Name, Column: c}))
+		}
+		fields := j.Dict{
+			j.Id("Name"):       j.Lit(idx.Name),
+			j.Id("Method"):     j.Lit(idx.Method),
+			j.Id("Keys"):       j.Index().Qual("github.com/pindamonhangaba/tabua", "IndexKey").Values(keys...),
+			j.Id("Definition"): j.Lit(idx.Definition),
+		}
+		if idx.Unique {
+			fields[j.Id("Unique")] = j.True()
+		}
+		if idx.Primary {
+			fields[j.Id("Primary")] = j.True()
+		}
+		if len(include) > 0 {
+			fields[j.Id("Include")] = j.Index().Qual("github.com/pindamonhangaba/tabua", "Column").Values(include...)
+		}
+		if idx.Where != nil {
+			fields[j.Id("Where")] = j.Lit(*idx.Where)
+		}
+		tableIndexes = append(tableIndexes, j.Values(fields))
+	}
+	file.Comment("Indexes implements the tabua.Indexer interface.")
+	file.Func().Params(
+		j.Id("t").Id(tableName),
+	).Id("Indexes").Params().Index().Qual("github.com/pindamonhangaba/tabua", "Index").Block(
+		j.Return(j.Index().Qual("github.com/pindamonhangaba/tabua", "Index").Values(tableIndexes...)),
+	)
 	file.Line()
 
 	// constraints types
@@ -73,8 +263,15 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 	for _, c := range t.Constraints {
 		n := cstname(c.Name)
 
-		file.Commentf("%s is a constraintforthe table \"%s\", a %s", n, tableName, c.Type)
-		file.Const().Id(n).Op("=").Lit(c.Name)
+		file.Commentf("%s is a constraint for the table \"%s\", a %s", n, tableName, c.Type)
+		file.Type().Id(n).Struct()
+
+		file.Comment("Name implements the tabua.Namer interface.")
+		file.Func().Params(
+			j.Id("c").Id(n),
+		).Id("Name").Params().String().Block(
+			j.Return(j.Lit(c.Name)),
+		)
 
 		file.Comment("Type implements tbu.Constrainer")
 		file.Func().Params(
@@ -93,8 +290,7 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 		case tbu.ConstraintUnique:
 			cols := []j.Code{}
 			for _, c := range c.ColumnsLocal {
-				colName := colName(c.Table, c.Column)
-				cols = append(cols, j.Id(colName))
+				cols = append(cols, column(c))
 			}
 			file.Comment("Uniques implements tbu.UniqueConstrainer")
 			file.Func().Params(
@@ -106,8 +302,7 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 		case tbu.ConstraintCheck:
 			cols := []j.Code{}
 			for _, c := range c.ColumnsLocal {
-				colName := colName(c.Table, c.Column)
-				cols = append(cols, j.Id(colName))
+				cols = append(cols, column(c))
 			}
 			file.Comment("Columns implements tbu.CheckConstrainer")
 			file.Func().Params(
@@ -119,8 +314,7 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 		case tbu.ConstraintPK:
 			cols := []j.Code{}
 			for _, c := range c.ColumnsLocal {
-				colName := colName(c.Table, c.Column)
-				cols = append(cols, j.Id(colName))
+				cols = append(cols, column(c))
 			}
 			file.Comment("Keys implements tbu.PKConstrainer")
 			file.Func().Params(
@@ -133,12 +327,10 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 			cols := []j.Code{}
 			colsf := []j.Code{}
 			for _, c := range c.ColumnsLocal {
-				colName := colName(c.Table, c.Column)
-				cols = append(cols, j.Id(colName))
+				cols = append(cols, column(c))
 			}
 			for _, c := range c.ColumnsForeign {
-				colName := colName(c.Table, c.Column)
-				colsf = append(colsf, j.Qual(pkgPath+packageFilename(c.Table), colName))
+				colsf = append(colsf, column(c))
 			}
 			file.Comment("Key implements tbu.FKConstrainer")
 			file.Func().Params(
@@ -158,12 +350,29 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 	// implement tabua.Column
 	for _, c := range t.Columns {
 		colName := columnName(t.Name, c.Name)
-		ctype := reType(c, c.NonNull)
+		ctype, ok := g.columnType(t, c)
+		if !ok {
+			g.Warnings = append(g.Warnings, fmt.Sprintf("%s.%s: unknown type %s (%s), generated as %s", t.Name, c.Name, c.UDTName, c.DataType, ctype.String()))
+		}
 		file.Commentf("%s is the column type for the table \"%s\", a %s", colName, tableName, ctype.String())
-		if len(ctype.Name()) == 0 || len(ctype.PkgPath()) == 0 {
-			file.Type().Id(colName).Id(ctype.String())
-		} else {
-			file.Type().Id(colName).Qual(ctype.PkgPath(), ctype.Name())
+		file.Type().Id(colName).Add(goType(ctype))
+
+		// a defined type doesn't inherit the methods of an imported type,
+		// delegate to it so the driver can bind and scan the column
+		if ctype.Imported() && ctype.Dims == 0 {
+			file.Comment("Value implements the driver.Valuer interface.")
+			file.Func().Params(
+				j.Id("c").Id(colName),
+			).Id("Value").Params().Params(j.Qual("database/sql/driver", "Value"), j.Error()).Block(
+				j.Return(j.Qual("github.com/pindamonhangaba/tabua", "ValueOf").Call(goType(ctype).Parens(j.Id("c")))),
+			)
+
+			file.Comment("Scan implements the sql.Scanner interface.")
+			file.Func().Params(
+				j.Id("c").Op("*").Id(colName),
+			).Id("Scan").Params(j.Id("src").Interface()).Error().Block(
+				j.Return(j.Qual("github.com/pindamonhangaba/tabua", "ScanInto").Call(j.Parens(j.Op("*").Add(goType(ctype))).Parens(j.Id("c")), j.Id("src"))),
+			)
 		}
 
 		file.Comment("Name implements the tabua.Namer interface.")
@@ -187,6 +396,46 @@ func buildTable(t reverse.Table, pkgPath string) *j.File {
 			j.Return(j.Lit(c.NonNull)),
 		)
 
+		// limits checked by tabua.Validate
+		if c.Dimension == 0 && c.Length != nil {
+			file.Comment("Length implements the tabua.Limited interface.")
+			file.Func().Params(
+				j.Id("c").Id(colName),
+			).Id("Length").Params().Int().Block(
+				j.Return(j.Lit(*c.Length)),
+			)
+		}
+		if c.Dimension == 0 && c.Precision != nil {
+			scale := 0
+			if c.Scale != nil {
+				scale = *c.Scale
+			}
+			file.Comment("Precision implements the tabua.Precise interface.")
+			file.Func().Params(
+				j.Id("c").Id(colName),
+			).Id("Precision").Params().Int().Block(
+				j.Return(j.Lit(*c.Precision)),
+			)
+			file.Comment("Scale implements the tabua.Precise interface.")
+			file.Func().Params(
+				j.Id("c").Id(colName),
+			).Id("Scale").Params().Int().Block(
+				j.Return(j.Lit(scale)),
+			)
+		}
+		if c.Dimension == 0 && len(c.Enum) > 0 {
+			labels := []j.Code{}
+			for _, l := range c.Enum {
+				labels = append(labels, j.Lit(l))
+			}
+			file.Comment("Labels implements the tabua.Enumerated interface.")
+			file.Func().Params(
+				j.Id("c").Id(colName),
+			).Id("Labels").Params().Index().String().Block(
+				j.Return(j.Index().String().Values(labels...)),
+			)
+		}
+
 		file.Comment("Table implements the tabua.Column interface.")
 		file.Func().Params(
 			j.Id("c").Id(colName),
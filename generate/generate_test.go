@@ -0,0 +1,176 @@
+package generate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pindamonhangaba/tabua/reverse"
+)
+
+// render generates the code of t
+func render(t *testing.T, g *Generator, tb reverse.Table) string {
+	t.Helper()
+	f, _ := g.Run(tb)
+	return fmt.Sprintf("%#v", f)
+}
+
+// contains fails t for each of want missing from code
+func contains(t *testing.T, code string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(code, w) {
+			t.Errorf("generated code is missing %q:\n%s", w, code)
+		}
+	}
+}
+
+var users = reverse.Table{
+	Name:   "users",
+	Schema: "auth",
+	Columns: []reverse.Column{
+		{Name: "id", Position: 1, UDTName: "int8", DataType: "bigint", NonNull: true},
+		{Name: "email", Position: 2, UDTName: "text", DataType: "text", NonNull: true},
+	},
+	Constraints: []reverse.Constraint{
+		{Name: "users_pkey", Type: "PRIMARY KEY", Definition: "PRIMARY KEY (id)", ColumnsLocal: []reverse.ConstraintColumn{{Schema: "auth", Table: "users", Column: "id"}}},
+	},
+}
+
+func TestSchema(t *testing.T) {
+	code := render(t, &Generator{PackagePath: "example.com/db/"}, users)
+	contains(t, code,
+		"package users",
+		"func (t Users) Name() string {\n\treturn \"users\"\n}",
+		"func (t Users) Schema() string {\n\treturn \"auth\"\n}",
+	)
+
+	noSchema := users
+	noSchema.Schema = ""
+	if code := render(t, &Generator{}, noSchema); strings.Contains(code, "Schema()") {
+		t.Errorf("a table without schema implements Schemer:\n%s", code)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	register := "func init() {\n\ttabua.Register(Users{})\n}"
+	if code := render(t, &Generator{}, users); strings.Contains(code, "Register") {
+		t.Errorf("registered a table by default:\n%s", code)
+	}
+	contains(t, render(t, &Generator{Register: true}, users), register)
+}
+
+func TestDir(t *testing.T) {
+	roles := reverse.Table{Name: "user_roles", Schema: "auth"}
+	if got := (&Generator{}).Dir(roles); got != "userroles" {
+		t.Errorf("got %s, want userroles", got)
+	}
+	if got := (&Generator{SchemaDirs: true}).Dir(roles); got != "auth/userroles" {
+		t.Errorf("got %s, want auth/userroles", got)
+	}
+}
+
+func TestForeignSchema(t *testing.T) {
+	posts := reverse.Table{
+		Name:   "posts",
+		Schema: "app",
+		Columns: []reverse.Column{
+			{Name: "author_id", Position: 1, UDTName: "int8", DataType: "bigint", NonNull: true},
+		},
+		Constraints: []reverse.Constraint{{
+			Name:           "posts_author_id_fkey",
+			Type:           "FOREIGN KEY",
+			ColumnsLocal:   []reverse.ConstraintColumn{{Schema: "app", Table: "posts", Column: "author_id"}},
+			ColumnsForeign: []reverse.ConstraintColumn{{Schema: "auth", Table: "users", Column: "id"}},
+		}},
+	}
+	code := render(t, &Generator{PackagePath: "example.com/db/", SchemaDirs: true}, posts)
+	contains(t, code, `users "example.com/db/auth/users"`, "users.Users{}.ID")
+}
+
+func TestDeterministic(t *testing.T) {
+	shuffled := users
+	shuffled.Columns = []reverse.Column{users.Columns[1], users.Columns[0]}
+	shuffled.Constraints = append([]reverse.Constraint{{Name: "users_email_key", Type: "UNIQUE"}}, users.Constraints...)
+	sorted := users
+	sorted.Constraints = append(append([]reverse.Constraint(nil), users.Constraints...), reverse.Constraint{Name: "users_email_key", Type: "UNIQUE"})
+
+	g := &Generator{}
+	if a, b := render(t, g, shuffled), render(t, g, sorted); a != b {
+		t.Errorf("generated code depends on the reversed order:\n%s\n%s", a, b)
+	}
+	if shuffled.Columns[0].Name != "email" {
+		t.Error("generating reordered the columns of its input")
+	}
+}
+
+func TestUnknownType(t *testing.T) {
+	tb := reverse.Table{Name: "shapes", Columns: []reverse.Column{
+		{Name: "id", Position: 1, UDTName: "int4", DataType: "integer", NonNull: true},
+		{Name: "geom", Position: 2, UDTName: "geometry", DataType: "USER-DEFINED", NonNull: true},
+	}}
+	g := &Generator{}
+	code := render(t, g, tb)
+	contains(t, code, "type ID int", "type Geom []uint8")
+	if len(g.Warnings) != 1 || !strings.Contains(g.Warnings[0], "shapes.geom: unknown type geometry") {
+		t.Errorf("got warnings %q", g.Warnings)
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	posts := reverse.Table{Name: "posts", Schema: "public", Columns: []reverse.Column{
+		{Name: "id", Position: 1, UDTName: "int8", DataType: "bigint", NonNull: true},
+		{Name: "deleted_at", Position: 2, UDTName: "timestamptz", DataType: "timestamp with time zone"},
+	}}
+	deleted := "func (t Posts) DeletedColumn() tabua.Column {\n\treturn t.DeletedAt\n}"
+	contains(t, render(t, &Generator{SoftDeleteColumn: "deleted_at"}, posts), deleted)
+	contains(t, render(t, &Generator{SoftDelete: map[string]string{"public.posts": "deleted_at"}}, posts), deleted)
+
+	if code := render(t, &Generator{SoftDeleteColumn: "id"}, posts); strings.Contains(code, "DeletedColumn") {
+		t.Errorf("a non null column marks rows deleted:\n%s", code)
+	}
+	g := &Generator{SoftDelete: map[string]string{"posts": "id"}}
+	if code := render(t, g, posts); strings.Contains(code, "DeletedColumn") || len(g.Warnings) != 1 {
+		t.Errorf("got warnings %q for a non null soft delete column:\n%s", g.Warnings, code)
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	id, email, lower := "id", "email", "lower(email)"
+	where := "(deleted_at IS NULL)"
+	tb := users
+	tb.Indexes = []reverse.Index{
+		{Name: "users_pkey", Method: "btree", Unique: true, Primary: true, Definition: "CREATE UNIQUE INDEX users_pkey ON auth.users USING btree (id)",
+			Keys: []reverse.IndexKey{{Column: &id}}},
+		{Name: "users_lower_email_idx", Method: "btree", Unique: true, Definition: "CREATE UNIQUE INDEX users_lower_email_idx ON auth.users USING btree (lower(email)) WHERE (deleted_at IS NULL)",
+			Keys: []reverse.IndexKey{{Expression: &lower}}, Where: &where},
+		{Name: "users_id_idx", Method: "hash", Definition: "CREATE INDEX users_id_idx ON auth.users USING hash (id) INCLUDE (email)",
+			Keys: []reverse.IndexKey{{Column: &id}}, Include: []string{email}},
+	}
+	code := render(t, &Generator{}, tb)
+	contains(t, code, `func (t Users) Indexes() []tabua.Index {
+	return []tabua.Index{{
+		Definition: "CREATE INDEX users_id_idx ON auth.users USING hash (id) INCLUDE (email)",
+		Include:    []tabua.Column{Users{}.Email},
+		Keys:       []tabua.IndexKey{{Column: Users{}.ID}},
+		Method:     "hash",
+		Name:       "users_id_idx",
+	}, {
+		Definition: "CREATE UNIQUE INDEX users_lower_email_idx ON auth.users USING btree (lower(email)) WHERE (deleted_at IS NULL)",
+		Keys:       []tabua.IndexKey{{Expression: "lower(email)"}},
+		Method:     "btree",
+		Name:       "users_lower_email_idx",
+		Unique:     true,
+		Where:      "(deleted_at IS NULL)",
+	}, {
+		Definition: "CREATE UNIQUE INDEX users_pkey ON auth.users USING btree (id)",
+		Keys:       []tabua.IndexKey{{Column: Users{}.ID}},
+		Method:     "btree",
+		Name:       "users_pkey",
+		Primary:    true,
+		Unique:     true,
+	}}
+}`)
+
+	contains(t, render(t, &Generator{}, users), "func (t Users) Indexes() []tabua.Index {\n\treturn []tabua.Index{}\n}")
+}
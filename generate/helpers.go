@@ -1,55 +1,86 @@
-package generate
-
-import (
-	t "github.com/pindamonhangaba/tabua/generate/types"
-	"github.com/pindamonhangaba/tabua/reverse"
-	"github.com/serenize/snaker"
-	"reflect"
-	"strings"
-)
-
-func colName(table, column string) string {
-	table = snaker.SnakeToCamel(table)
-	column = snaker.SnakeToCamel(column)
-	if table == column {
-		return column + "Col"
-	}
-	return column
-}
-func columnName(tab, col string) string { return colName(tab, col) }
-func cstname(s string) string {
-	s = strings.Replace(s, ".", "", -1)
-	s = strings.Replace(s, "-", "", -1)
-	return "CS" + snaker.SnakeToCamel(s)
-}
-func camel(s string) string { return snaker.SnakeToCamel(s) }
-func camelLower(s string) string {
-	n := snaker.SnakeToCamel(s)
-	return strings.ToLower(string(n[0])) + n[1:]
-}
-func packageFilename(s string) string { return strings.Replace(s, "_", "", -1) }
-func reType(col reverse.Column, nnull bool) reflect.Type {
-
-	udt, ok := t.SQLType2Type(t.SQLType{Name: col.UDTName, Dimension: int(col.Dimension)}, nnull)
-	if !ok {
-		daty, _ := t.SQLType2Type(t.SQLType{Name: col.DataType}, nnull)
-		return daty
-	}
-	return udt
-}
-func isJSON(s string) bool {
-	tps := strings.Split(s, ", ")
-	return tps[0] == t.JSON || tps[0] == t.JSONB
-}
-func isString(s string) bool {
-	tps := strings.Split(s, ", ")
-	return !(tps[0] == t.JSON || tps[0] == t.JSONB) && t.SQLTypes[tps[0]] == t.TextSQLType
-}
-func isTime(s string) bool {
-	tps := strings.Split(s, ", ")
-	return t.SQLTypes[tps[0]] == t.TimeSQLType
-}
-func isBlob(s string) bool {
-	tps := strings.Split(s, ", ")
-	return t.SQLTypes[tps[0]] == t.BlobSQLType
-}
+package generate
+
+import (
+	j "github.com/dave/jennifer/jen"
+	t "github.com/pindamonhangaba/tabua/generate/types"
+	"github.com/pindamonhangaba/tabua/reverse"
+	"github.com/serenize/snaker"
+	"strings"
+)
+
+func colName(table, column string) string {
+	table = snaker.SnakeToCamel(table)
+	column = snaker.SnakeToCamel(column)
+	if table == column {
+		return column + "Col"
+	}
+	return column
+}
+func columnName(tab, col string) string { return colName(tab, col) }
+func cstname(s string) string {
+	s = strings.Replace(s, ".", "", -1)
+	s = strings.Replace(s, "-", "", -1)
+	return "CS" + snaker.SnakeToCamel(s)
+}
+func camel(s string) string { return snaker.SnakeToCamel(s) }
+func camelLower(s string) string {
+	n := snaker.SnakeToCamel(s)
+	return strings.ToLower(string(n[0])) + n[1:]
+}
+
+// sorted returns a copy of t ordered as reverse.Sort does, the same schema always generates the same code
+func sorted(t reverse.Table) reverse.Table {
+	t.Columns = append([]reverse.Column(nil), t.Columns...)
+	t.Constraints = append([]reverse.Constraint(nil), t.Constraints...)
+	t.Indexes = append([]reverse.Index(nil), t.Indexes...)
+	tables := []reverse.Table{t}
+	reverse.Sort(tables)
+	return tables[0]
+}
+func packageFilename(s string) string { return strings.Replace(s, "_", "", -1) }
+func reType(col reverse.Column, nnull bool) (t.GoType, bool) {
+
+	udt, ok := t.Lookup(t.SQLType{Name: col.UDTName, Dimension: int(col.Dimension)}, nnull)
+	if !ok {
+		return t.Lookup(t.SQLType{Name: col.DataType, Dimension: int(col.Dimension)}, nnull)
+	}
+	return udt, true
+}
+func goType(gt t.GoType) *j.Statement {
+	s := j.Empty()
+	for i := 0; i < gt.Dims; i++ {
+		s = s.Index()
+	}
+	if gt.Imported() {
+		return s.Qual(gt.PkgPath, gt.Name)
+	}
+	return s.Id(gt.Name)
+}
+
+// isVersion reports if c can lock a table, integers are incremented and timestamps set to the current time
+func isVersion(c reverse.Column) bool {
+	if !c.NonNull || c.Dimension > 0 {
+		return false
+	}
+	switch c.UDTName {
+	case "int2", "int4", "int8", "timestamp", "timestamptz":
+		return true
+	}
+	return false
+}
+func isJSON(s string) bool {
+	tps := strings.Split(s, ", ")
+	return tps[0] == t.JSON || tps[0] == t.JSONB
+}
+func isString(s string) bool {
+	tps := strings.Split(s, ", ")
+	return !(tps[0] == t.JSON || tps[0] == t.JSONB) && t.SQLTypes[tps[0]] == t.TextSQLType
+}
+func isTime(s string) bool {
+	tps := strings.Split(s, ", ")
+	return t.SQLTypes[tps[0]] == t.TimeSQLType
+}
+func isBlob(s string) bool {
+	tps := strings.Split(s, ", ")
+	return t.SQLTypes[tps[0]] == t.BlobSQLType
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// GoType names a Go type for generated code
+type GoType struct {
+	// PkgPath is the import path of a named type, empty for builtin types
+	PkgPath string
+	// Name is the type name, or the full type for builtin and composite types
+	Name string
+	// Dims is the number of slice dimensions wrapping the type
+	Dims int
+}
+
+// String returns the type as written in Go code
+func (g GoType) String() string {
+	s := strings.Repeat("[]", g.Dims)
+	if len(g.PkgPath) > 0 {
+		pkg := g.PkgPath[strings.LastIndex(g.PkgPath, "/")+1:]
+		// gopkg.in/pkg.v3 is package pkg
+		if i := strings.Index(pkg, "."); i > 0 {
+			pkg = pkg[:i]
+		}
+		return s + pkg + "." + g.Name
+	}
+	return s + g.Name
+}
+
+// Imported reports if the type is a named type from another package
+func (g GoType) Imported() bool {
+	return len(g.PkgPath) > 0
+}
+
+// TypeOf returns the GoType of t
+func TypeOf(t reflect.Type) GoType {
+	if len(t.Name()) == 0 || len(t.PkgPath()) == 0 {
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+			e := TypeOf(t.Elem())
+			e.Dims++
+			return e
+		}
+		return GoType{Name: t.String()}
+	}
+	return GoType{PkgPath: t.PkgPath(), Name: t.Name()}
+}
+
+// ParseGoType parses a type written as "import/path.Name", a name without
+// a package path is a builtin type, leading [] are slice dimensions
+func ParseGoType(s string) GoType {
+	g := GoType{}
+	for strings.HasPrefix(s, "[]") {
+		g.Dims++
+		s = s[2:]
+	}
+	i := strings.LastIndex(s, ".")
+	if i < 0 || strings.LastIndex(s, "/") > i {
+		g.Name = s
+		return g
+	}
+	g.PkgPath, g.Name = s[:i], s[i+1:]
+	return g
+}
+
+type registered struct {
+	nonNull, null GoType
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]registered{}
+)
+
+// Register maps the SQL type udt, a udt_name or data_type, to the Go types
+// used for non null and nullable columns, overriding SQLType2Type
+func Register(udt string, nonNull, null GoType) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(udt)] = registered{nonNull, null}
+}
+
+// Lookup returns the Go type for st, checking registered types before SQLType2Type
+func Lookup(st SQLType, nnull bool) (GoType, bool) {
+	name := strings.ToLower(st.Name)
+	isArray := strings.HasPrefix(name, "_") || st.Dimension > 0
+
+	registryMu.RLock()
+	r, ok := registry[name]
+	if !ok && isArray {
+		r, ok = registry[strings.TrimPrefix(name, "_")]
+	} else if ok {
+		// a registered array type is used as is
+		isArray = false
+	}
+	registryMu.RUnlock()
+
+	if !ok {
+		t, ok := SQLType2Type(st, nnull)
+		return TypeOf(t), ok
+	}
+	g := r.null
+	if nnull {
+		g = r.nonNull
+	}
+	if isArray {
+		d := st.Dimension
+		if d < 1 {
+			d = 1
+		}
+		g.Dims += d
+	}
+	return g, true
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGoType(t *testing.T) {
+	cases := []struct {
+		s    string
+		want GoType
+		str  string
+	}{
+		{"string", GoType{Name: "string"}, "string"},
+		{"[]int64", GoType{Name: "int64", Dims: 1}, "[]int64"},
+		{"github.com/google/uuid.UUID", GoType{PkgPath: "github.com/google/uuid", Name: "UUID"}, "uuid.UUID"},
+		{"[][]gopkg.in/guregu/null.v3.String", GoType{PkgPath: "gopkg.in/guregu/null.v3", Name: "String", Dims: 2}, "[][]null.String"},
+	}
+	for _, c := range cases {
+		got := ParseGoType(c.s)
+		if got != c.want || got.String() != c.str {
+			t.Errorf("%s: got %+v %s, want %+v %s", c.s, got, got.String(), c.want, c.str)
+		}
+	}
+}
+
+func TestTypeOf(t *testing.T) {
+	cases := []struct {
+		t    reflect.Type
+		want GoType
+	}{
+		{StringType, GoType{Name: "string"}},
+		{BytesType, GoType{Name: "[]uint8"}},
+		{reflect.TypeOf([][]int{}), GoType{Name: "int", Dims: 2}},
+		{NullString, GoType{PkgPath: "gopkg.in/guregu/null.v3", Name: "String"}},
+	}
+	for _, c := range cases {
+		if got := TypeOf(c.t); got != c.want {
+			t.Errorf("%v: got %+v, want %+v", c.t, got, c.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	uuid := GoType{PkgPath: "github.com/google/uuid", Name: "UUID"}
+	nullUUID := GoType{PkgPath: "github.com/google/uuid", Name: "NullUUID"}
+	Register("test_uuid", uuid, nullUUID)
+	Register("_test_tags", GoType{Name: "Tags", PkgPath: "example.com/tags"}, GoType{Name: "Tags", PkgPath: "example.com/tags"})
+
+	cases := []struct {
+		st    SQLType
+		nnull bool
+		want  GoType
+		ok    bool
+	}{
+		{SQLType{Name: "test_uuid"}, true, uuid, true},
+		{SQLType{Name: "TEST_UUID"}, false, nullUUID, true},
+		{SQLType{Name: "_test_uuid"}, true, GoType{PkgPath: uuid.PkgPath, Name: "UUID", Dims: 1}, true},
+		{SQLType{Name: "_test_tags"}, true, GoType{PkgPath: "example.com/tags", Name: "Tags"}, true},
+		{SQLType{Name: "int8"}, true, GoType{Name: "int64"}, true},
+		{SQLType{Name: "test_unknown"}, true, GoType{Name: "[]uint8"}, false},
+	}
+	for _, c := range cases {
+		got, ok := Lookup(c.st, c.nnull)
+		if got != c.want || ok != c.ok {
+			t.Errorf("%+v non null %v: got %+v %v, want %+v %v", c.st, c.nnull, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -94,11 +94,14 @@ var (
 	LongText   = "LONGTEXT"
 	UUID       = "UUID"
 
-	Date       = "DATE"
-	DateTime   = "DATETIME"
-	Time       = "TIME"
-	TimeStamp  = "TIMESTAMP"
-	TimeStampz = "TIMESTAMPZ"
+	Date        = "DATE"
+	DateTime    = "DATETIME"
+	Time        = "TIME"
+	TimeTz      = "TIMETZ"
+	TimeStamp   = "TIMESTAMP"
+	TimeStampz  = "TIMESTAMPZ"
+	TimeStampTz = "TIMESTAMPTZ"
+	Interval    = "INTERVAL"
 
 	Decimal = "DECIMAL"
 	Numeric = "NUMERIC"
@@ -147,11 +150,14 @@ var (
 		UUID:       TextSQLType,
 		Clob:       TextSQLType,
 
-		Date:       TimeSQLType,
-		DateTime:   TimeSQLType,
-		Time:       TimeSQLType,
-		TimeStamp:  TimeSQLType,
-		TimeStampz: TimeSQLType,
+		Date:        TimeSQLType,
+		DateTime:    TimeSQLType,
+		Time:        TimeSQLType,
+		TimeTz:      TimeSQLType,
+		TimeStamp:   TimeSQLType,
+		TimeStampz:  TimeSQLType,
+		TimeStampTz: TimeSQLType,
+		Interval:    TextSQLType,
 
 		Decimal: NumericSQLType,
 		Numeric: NumericSQLType,
@@ -174,6 +180,82 @@ var (
 		BigSerial: NumericSQLType,
 	}
 
+	// PostgresTypes maps PostgreSQL udt_name and information_schema data_type
+	// values to the SQL types above, udt names of arrays are prefixed with _
+	PostgresTypes = map[string]string{
+		"int2":     SmallInt,
+		"int4":     Int,
+		"int8":     BigInt,
+		"smallint": SmallInt,
+		"integer":  Int,
+		"bigint":   BigInt,
+		"oid":      BigInt,
+		"xid":      BigInt,
+		"cid":      BigInt,
+
+		"float4":           Real,
+		"float8":           Double,
+		"real":             Real,
+		"double precision": Double,
+		"numeric":          Numeric,
+		"money":            Text,
+
+		"bool":    Bool,
+		"boolean": Bool,
+
+		"bpchar":            Char,
+		"char":              Char,
+		"character":         Char,
+		"varchar":           Varchar,
+		"character varying": Varchar,
+		"text":              Text,
+		"citext":            Text,
+		"name":              Varchar,
+		"uuid":              UUID,
+		"xml":               Text,
+		"tsvector":          Text,
+		"tsquery":           Text,
+		"bit":               Text,
+		"varbit":            Text,
+		"bit varying":       Text,
+		"inet":              Text,
+		"cidr":              Text,
+		"macaddr":           Text,
+		"macaddr8":          Text,
+		"point":             Text,
+		"line":              Text,
+		"lseg":              Text,
+		"box":               Text,
+		"path":              Text,
+		"polygon":           Text,
+		"circle":            Text,
+		"pg_lsn":            Text,
+		"regclass":          Text,
+		"regproc":           Text,
+		"regtype":           Text,
+		"int4range":         Text,
+		"int8range":         Text,
+		"numrange":          Text,
+		"tsrange":           Text,
+		"tstzrange":         Text,
+		"daterange":         Text,
+
+		"date":                        Date,
+		"time":                        Time,
+		"timetz":                      TimeTz,
+		"timestamp":                   TimeStamp,
+		"timestamptz":                 TimeStampTz,
+		"time without time zone":      Time,
+		"time with time zone":         TimeTz,
+		"timestamp without time zone": TimeStamp,
+		"timestamp with time zone":    TimeStampTz,
+		"interval":                    Interval,
+
+		"bytea": Bytea,
+		"json":  JSON,
+		"jsonb": JSONB,
+	}
+
 	intTypes  = sort.StringSlice{"*int", "*int16", "*int32", "*int8"}
 	uintTypes = sort.StringSlice{"*uint", "*uint16", "*uint32", "*uint8"}
 )
@@ -227,6 +309,7 @@ var (
 	TimeType = reflect.TypeOf(cTimeDefault)
 
 	SliceInt8 = reflect.TypeOf([]int8{})
+	BytesType = reflect.TypeOf([]byte{})
 )
 
 // Golang pointer types
@@ -272,10 +355,15 @@ var (
 )
 
 // SQLType2Type default sql type change to golang types
+// Unknown types return []byte, what the driver scans them into, and ok false
 func SQLType2Type(st SQLType, nnull bool) (t reflect.Type, ok bool) {
-	name := strings.ToUpper(st.Name)
+	name := strings.ToLower(st.Name)
 	isArray := strings.HasPrefix(name, "_") || st.Dimension > 0
-	name = strings.Replace(name, "_", "", -1)
+	name = strings.TrimPrefix(name, "_")
+	if pg, has := PostgresTypes[name]; has {
+		name = pg
+	}
+	name = strings.ToUpper(name)
 	var res reflect.Type
 
 	switch name {
@@ -322,13 +410,13 @@ func SQLType2Type(st SQLType, nnull bool) (t reflect.Type, ok bool) {
 		} else {
 			res, ok = NullBool, true
 		}
-	case DateTime, Date, Time, TimeStamp, TimeStampz:
+	case DateTime, Date, Time, TimeTz, TimeStamp, TimeStampz, TimeStampTz:
 		if nnull {
 			res, ok = TimeType, true
 		} else {
 			res, ok = NullTime, true
 		}
-	case Decimal, Numeric:
+	case Decimal, Numeric, Interval:
 		if nnull {
 			res, ok = StringType, true
 		} else {
@@ -341,14 +429,19 @@ func SQLType2Type(st SQLType, nnull bool) (t reflect.Type, ok bool) {
 			res, ok = NullJSONText, true
 		}
 	default:
-		res, ok = SliceInt8, false
+		return BytesType, false
 	}
 
 	if isArray {
-		res = makeDimensions(res, st.Dimension)
+		// attndims isn't always set for array columns
+		d := st.Dimension
+		if d < 1 {
+			d = 1
+		}
+		res = makeDimensions(res, d)
 	}
 
-	return res, true
+	return res, ok
 }
 
 func makeDimensions(t reflect.Type, d int) reflect.Type {
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSQLType2Type(t *testing.T) {
+	cases := []struct {
+		st    SQLType
+		nnull bool
+		want  reflect.Type
+		ok    bool
+	}{
+		{SQLType{Name: "int4"}, true, IntType, true},
+		{SQLType{Name: "int8"}, true, Int64Type, true},
+		{SQLType{Name: "int8"}, false, NullInt, true},
+		{SQLType{Name: "float4"}, true, Float32Type, true},
+		{SQLType{Name: "double precision"}, true, Float64Type, true},
+		{SQLType{Name: "numeric"}, true, StringType, true},
+		{SQLType{Name: "varchar"}, false, NullString, true},
+		{SQLType{Name: "character varying"}, true, StringType, true},
+		{SQLType{Name: "uuid"}, true, StringType, true},
+		{SQLType{Name: "bool"}, false, NullBool, true},
+		{SQLType{Name: "timestamptz"}, true, TimeType, true},
+		{SQLType{Name: "date"}, false, NullTime, true},
+		{SQLType{Name: "bytea"}, false, BytesType, true},
+		{SQLType{Name: "jsonb"}, true, JSONText, true},
+		{SQLType{Name: "json"}, false, NullJSONText, true},
+		{SQLType{Name: "_int4"}, true, reflect.TypeOf([]int{}), true},
+		{SQLType{Name: "_text", Dimension: 2}, true, reflect.TypeOf([][]string{}), true},
+		{SQLType{Name: "INTEGER"}, true, IntType, true},
+		{SQLType{Name: "mood"}, true, BytesType, false},
+	}
+	for _, c := range cases {
+		got, ok := SQLType2Type(c.st, c.nnull)
+		if got != c.want || ok != c.ok {
+			t.Errorf("%+v non null %v: got %v %v, want %v %v", c.st, c.nnull, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestPostgresTypesAreKnown(t *testing.T) {
+	for udt, name := range PostgresTypes {
+		if _, ok := SQLTypes[name]; !ok {
+			t.Errorf("%s maps to %s, which has no SQL type class", udt, name)
+		}
+		if _, ok := SQLType2Type(SQLType{Name: udt}, true); !ok {
+			t.Errorf("%s has no Go type", udt)
+		}
+	}
+}
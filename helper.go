@@ -15,3 +15,67 @@ func (q Sq) SQL() string {
 func (q Sq) Args() []interface{} {
 	return q.A
 }
+
+// PrimaryKey returns the primary key of a Constrained table
+func PrimaryKey(t Table) (PKConstrainer, bool) {
+	ct, ok := t.(Constrained)
+	if !ok {
+		return nil, false
+	}
+	for _, c := range ct.Constrainers() {
+		if pk, ok := c.(PKConstrainer); ok {
+			return pk, true
+		}
+	}
+	return nil, false
+}
+
+// ConstraintNamed returns the constraint of a Constrained table called name
+func ConstraintNamed(t Table, name string) (Constrainer, bool) {
+	ct, ok := t.(Constrained)
+	if !ok {
+		return nil, false
+	}
+	for _, c := range ct.Constrainers() {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// IndexOn returns an index of an Indexer table able to look up rows by cols,
+// a full index whose leading keys are cols, in any order. A column given twice counts once
+func IndexOn(t Table, cols ...Column) (Index, bool) {
+	it, ok := t.(Indexer)
+	if !ok || len(cols) < 1 {
+		return Index{}, false
+	}
+	names := []string{}
+	seen := map[string]bool{}
+	for _, c := range cols {
+		if !seen[c.Name()] {
+			seen[c.Name()] = true
+			names = append(names, c.Name())
+		}
+	}
+	for _, idx := range it.Indexes() {
+		if len(idx.Where) > 0 || len(idx.Keys) < len(names) {
+			continue
+		}
+		leading := map[string]bool{}
+		for _, k := range idx.Keys[:len(names)] {
+			if k.Column != nil {
+				leading[k.Column.Name()] = true
+			}
+		}
+		found := true
+		for _, name := range names {
+			found = found && leading[name]
+		}
+		if found {
+			return idx, true
+		}
+	}
+	return Index{}, false
+}
@@ -0,0 +1,79 @@
+package tabua
+
+import "testing"
+
+// entries is indexed, without constraints
+type entries struct{}
+
+func (entries) Name() string              { return "entries" }
+func (entries) Constraints() []Constraint { return nil }
+func (entries) Columns() []Column {
+	return []Column{entryID(0), entryAccount(0), entryAt(""), entryStatus("")}
+}
+func (entries) Indexes() []Index {
+	return []Index{
+		{Name: "entries_pkey", Method: "btree", Unique: true, Primary: true, Keys: []IndexKey{{Column: entryID(0)}}},
+		{Name: "entries_lower_status_idx", Method: "btree", Keys: []IndexKey{{Expression: "lower(status)"}, {Column: entryAt("")}}},
+		{Name: "entries_open_idx", Method: "btree", Keys: []IndexKey{{Column: entryStatus("")}}, Where: "(status = 'open'::text)"},
+		{Name: "entries_account_id_at_idx", Method: "btree", Keys: []IndexKey{{Column: entryAccount(0)}, {Column: entryAt("")}},
+			Include: []Column{entryStatus("")}},
+	}
+}
+
+type entryID int64
+
+func (entryID) Name() string    { return "id" }
+func (entryID) Table() Table    { return entries{} }
+func (entryID) SQLType() string { return "int8,bigint" }
+func (entryID) NonNull() bool   { return true }
+
+type entryAccount int64
+
+func (entryAccount) Name() string    { return "account_id" }
+func (entryAccount) Table() Table    { return entries{} }
+func (entryAccount) SQLType() string { return "int8,bigint" }
+func (entryAccount) NonNull() bool   { return true }
+
+type entryAt string
+
+func (entryAt) Name() string    { return "at" }
+func (entryAt) Table() Table    { return entries{} }
+func (entryAt) SQLType() string { return "timestamptz,timestamp with time zone" }
+func (entryAt) NonNull() bool   { return true }
+
+type entryStatus string
+
+func (entryStatus) Name() string    { return "status" }
+func (entryStatus) Table() Table    { return entries{} }
+func (entryStatus) SQLType() string { return "text,text" }
+func (entryStatus) NonNull() bool   { return true }
+
+func TestIndexOn(t *testing.T) {
+	cases := []struct {
+		name string
+		t    Table
+		cols []Column
+		want string
+	}{
+		{"primary key", entries{}, []Column{entryID(0)}, "entries_pkey"},
+		{"leading key", entries{}, []Column{entryAccount(0)}, "entries_account_id_at_idx"},
+		{"every key", entries{}, []Column{entryAccount(0), entryAt("")}, "entries_account_id_at_idx"},
+		{"any order", entries{}, []Column{entryAt(""), entryAccount(0)}, "entries_account_id_at_idx"},
+		{"repeated column", entries{}, []Column{entryID(0), entryID(0)}, "entries_pkey"},
+		{"repeated leading key", entries{}, []Column{entryAccount(0), entryAccount(0)}, "entries_account_id_at_idx"},
+		{"repeated second key", entries{}, []Column{entryAt(""), entryAt("")}, ""},
+		{"second key", entries{}, []Column{entryAt("")}, ""},
+		{"after an expression", entries{}, []Column{entryStatus(""), entryAt("")}, ""},
+		{"partial", entries{}, []Column{entryStatus("")}, ""},
+		{"included", entries{}, []Column{entryAccount(0), entryAt(""), entryStatus("")}, ""},
+		{"other keys", entries{}, []Column{entryID(0), entryAt("")}, ""},
+		{"no columns", entries{}, nil, ""},
+		{"not an indexer", accounts{}, []Column{accountID(0)}, ""},
+	}
+	for _, c := range cases {
+		idx, ok := IndexOn(c.t, c.cols...)
+		if ok != (c.want != "") || idx.Name != c.want {
+			t.Errorf("%s: got %q %v, want %q", c.name, idx.Name, ok, c.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tabua
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoQuery is returned when the Before of a Hook returns neither a query nor an error
+var ErrNoQuery = errors.New("hook returned no query")
+
+// QueryEvent describes an executed statement
+type QueryEvent struct {
+	Query    Querier
+	Duration time.Duration
+	// Rows is the number of rows affected or returned, -1 when unknown
+	Rows int64
+	Err  error
+}
+
+// Hook observes the statements an execution layer runs, the executors of crud and bulk.Copier
+// A Hook seeing queries as they're generated, wherever they run, is a GenerateHook
+type Hook interface {
+	// Before runs before q is executed, it returns q, a rewritten query, or an error to block it
+	Before(ctx context.Context, q Querier) (Querier, error)
+	// After runs once the query is executed
+	After(ctx context.Context, e QueryEvent)
+}
+
+// GenerateHook is a Hook also called by crud's builders on every query they generate,
+// so SQL run by another execution layer is rewritten or blocked as well
+// Generation has no context, Before still runs with the one of the execution
+type GenerateHook interface {
+	Hook
+	// Generate runs once q is generated, it returns q, a rewritten query, or an error returned instead of it
+	Generate(q Querier) (Querier, error)
+}
+
+// Hooks chains hooks, Before and Generate run them in order and After in reverse order
+type Hooks []Hook
+
+var (
+	sharedMu sync.RWMutex
+	shared   Hooks
+)
+
+// UseHooks adds hooks to the chain shared by every crud Builder and bulk.Copier,
+// it runs before the hooks they're given
+func UseHooks(hooks ...Hook) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	shared = append(shared, hooks...)
+}
+
+// SharedHooks returns a copy of the shared chain with hooks after it
+func SharedHooks(hooks ...Hook) Hooks {
+	sharedMu.RLock()
+	defer sharedMu.RUnlock()
+	return append(append(Hooks(nil), shared...), hooks...)
+}
+
+// ResetHooks empties the shared chain
+func ResetHooks() {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	shared = nil
+}
+
+// Before passes q through every hook, stopping at the first error
+// A hook returning no query blocks it with ErrNoQuery
+func (h Hooks) Before(ctx context.Context, q Querier) (Querier, error) {
+	for _, hk := range h {
+		next, err := hk.Before(ctx, q)
+		if err != nil {
+			return q, err
+		}
+		if next == nil {
+			return q, ErrNoQuery
+		}
+		q = next
+	}
+	return q, nil
+}
+
+// Generate passes q through every GenerateHook, stopping at the first error
+// A hook returning no query blocks it with ErrNoQuery
+func (h Hooks) Generate(q Querier) (Querier, error) {
+	for _, hk := range h {
+		g, ok := hk.(GenerateHook)
+		if !ok {
+			continue
+		}
+		next, err := g.Generate(q)
+		if err != nil {
+			return q, err
+		}
+		if next == nil {
+			return q, ErrNoQuery
+		}
+		q = next
+	}
+	return q, nil
+}
+
+// After passes e to every hook
+func (h Hooks) After(ctx context.Context, e QueryEvent) {
+	for i := len(h) - 1; i >= 0; i-- {
+		h[i].After(ctx, e)
+	}
+}
+
+// HookFuncs adapts functions to a GenerateHook, nil functions do nothing
+type HookFuncs struct {
+	GenerateFunc func(q Querier) (Querier, error)
+	BeforeFunc   func(ctx context.Context, q Querier) (Querier, error)
+	AfterFunc    func(ctx context.Context, e QueryEvent)
+}
+
+// Generate implements GenerateHook
+func (h HookFuncs) Generate(q Querier) (Querier, error) {
+	if h.GenerateFunc == nil {
+		return q, nil
+	}
+	return h.GenerateFunc(q)
+}
+
+// Before implements Hook
+func (h HookFuncs) Before(ctx context.Context, q Querier) (Querier, error) {
+	if h.BeforeFunc == nil {
+		return q, nil
+	}
+	return h.BeforeFunc(ctx, q)
+}
+
+// After implements Hook
+func (h HookFuncs) After(ctx context.Context, e QueryEvent) {
+	if h.AfterFunc != nil {
+		h.AfterFunc(ctx, e)
+	}
+}
@@ -0,0 +1,91 @@
+// Package hook holds tabua.Hook implementations for logging and reporting queries
+package hook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// Logger logs every executed statement
+type Logger struct {
+	// Log is the logger written to, slog.Default() when nil
+	Log *slog.Logger
+	// Level of successful statements, failed ones are logged as errors
+	Level slog.Level
+	// Args logs the bound arguments, which may hold sensitive values
+	Args bool
+}
+
+// Log returns a Logger writing to l at level
+func Log(l *slog.Logger, level slog.Level) Logger {
+	return Logger{Log: l, Level: level}
+}
+
+// Before implements tabua.Hook
+func (l Logger) Before(ctx context.Context, q tab.Querier) (tab.Querier, error) {
+	return q, nil
+}
+
+// After implements tabua.Hook
+func (l Logger) After(ctx context.Context, e tab.QueryEvent) {
+	level := l.Level
+	if e.Err != nil {
+		level = slog.LevelError
+	}
+	logger(l.Log).LogAttrs(ctx, level, "query", attrs(e, l.Args)...)
+}
+
+// SlowQuery reports statements taking longer than Threshold
+type SlowQuery struct {
+	Threshold time.Duration
+	// Report is called with slow statements, they're logged as warnings to slog.Default() when nil
+	Report func(ctx context.Context, e tab.QueryEvent)
+}
+
+// Slow returns a SlowQuery logging statements slower than threshold
+func Slow(threshold time.Duration) SlowQuery {
+	return SlowQuery{Threshold: threshold}
+}
+
+// Before implements tabua.Hook
+func (s SlowQuery) Before(ctx context.Context, q tab.Querier) (tab.Querier, error) {
+	return q, nil
+}
+
+// After implements tabua.Hook
+func (s SlowQuery) After(ctx context.Context, e tab.QueryEvent) {
+	if e.Duration < s.Threshold {
+		return
+	}
+	if s.Report != nil {
+		s.Report(ctx, e)
+		return
+	}
+	a := append(attrs(e, false), slog.Duration("threshold", s.Threshold))
+	slog.Default().LogAttrs(ctx, slog.LevelWarn, "slow query", a...)
+}
+
+func logger(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return slog.Default()
+	}
+	return l
+}
+
+func attrs(e tab.QueryEvent, args bool) []slog.Attr {
+	a := []slog.Attr{
+		slog.String("sql", e.Query.SQL()),
+		slog.Duration("duration", e.Duration),
+		slog.Int64("rows", e.Rows),
+	}
+	if args {
+		a = append(a, slog.Any("args", e.Query.Args()))
+	}
+	if e.Err != nil {
+		a = append(a, slog.String("error", e.Err.Error()))
+	}
+	return a
+}
@@ -0,0 +1,66 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+func event(d time.Duration, err error) tab.QueryEvent {
+	return tab.QueryEvent{Query: tab.Sq{S: "SELECT $1", A: []interface{}{"secret"}}, Duration: d, Rows: 2, Err: err}
+}
+
+func TestLogger(t *testing.T) {
+	cases := []struct {
+		name    string
+		l       Logger
+		e       tab.QueryEvent
+		want    []string
+		without []string
+	}{
+		{"success", Logger{Level: slog.LevelInfo}, event(time.Millisecond, nil),
+			[]string{"level=INFO", "msg=query", `sql="SELECT $1"`, "duration=1ms", "rows=2"}, []string{"secret", "error="}},
+		{"args", Logger{Level: slog.LevelDebug, Args: true}, event(time.Millisecond, nil),
+			[]string{"level=DEBUG", "args=[secret]"}, nil},
+		{"failure", Logger{Level: slog.LevelDebug}, event(time.Millisecond, errors.New("boom")),
+			[]string{"level=ERROR", "error=boom"}, nil},
+	}
+	for _, c := range cases {
+		buf := &bytes.Buffer{}
+		c.l.Log = slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		q, err := c.l.Before(context.Background(), c.e.Query)
+		if err != nil || q.SQL() != c.e.Query.SQL() {
+			t.Errorf("%s: Before got %v %v", c.name, q, err)
+		}
+		c.l.After(context.Background(), c.e)
+		for _, w := range c.want {
+			if !strings.Contains(buf.String(), w) {
+				t.Errorf("%s: log %q is missing %q", c.name, buf.String(), w)
+			}
+		}
+		for _, w := range c.without {
+			if strings.Contains(buf.String(), w) {
+				t.Errorf("%s: log %q has %q", c.name, buf.String(), w)
+			}
+		}
+	}
+}
+
+func TestSlowQuery(t *testing.T) {
+	reported := []time.Duration{}
+	s := SlowQuery{Threshold: 10 * time.Millisecond, Report: func(_ context.Context, e tab.QueryEvent) {
+		reported = append(reported, e.Duration)
+	}}
+	for _, d := range []time.Duration{time.Millisecond, 10 * time.Millisecond, time.Second} {
+		s.After(context.Background(), event(d, nil))
+	}
+	if len(reported) != 2 || reported[0] != 10*time.Millisecond || reported[1] != time.Second {
+		t.Errorf("got reports %v", reported)
+	}
+}
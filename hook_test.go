@@ -0,0 +1,116 @@
+package tabua
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// trace is a Hook recording its calls
+type trace struct {
+	name  string
+	calls *[]string
+	q     Querier
+	err   error
+}
+
+func (h trace) Before(ctx context.Context, q Querier) (Querier, error) {
+	*h.calls = append(*h.calls, "before "+h.name+" "+q.SQL())
+	if h.q != nil || h.err != nil {
+		return h.q, h.err
+	}
+	return q, nil
+}
+
+func (h trace) After(ctx context.Context, e QueryEvent) {
+	*h.calls = append(*h.calls, "after "+h.name+" "+e.Query.SQL())
+}
+
+func TestHooks(t *testing.T) {
+	calls := []string{}
+	hs := Hooks{
+		trace{name: "a", calls: &calls},
+		trace{name: "b", calls: &calls, q: Sq{S: "SELECT 2"}},
+		trace{name: "c", calls: &calls},
+	}
+	q, err := hs.Before(context.Background(), Sq{S: "SELECT 1"})
+	if err != nil || q.SQL() != "SELECT 2" {
+		t.Fatalf("got %v %v, want the rewritten query", q, err)
+	}
+	hs.After(context.Background(), QueryEvent{Query: q})
+	want := []string{
+		"before a SELECT 1", "before b SELECT 1", "before c SELECT 2",
+		"after c SELECT 2", "after b SELECT 2", "after a SELECT 2",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+}
+
+func TestHooksBlock(t *testing.T) {
+	blocked := errors.New("blocked")
+	cases := []struct {
+		name string
+		h    Hook
+		want error
+	}{
+		{"error", HookFuncs{BeforeFunc: func(context.Context, Querier) (Querier, error) { return nil, blocked }}, blocked},
+		{"no query", HookFuncs{BeforeFunc: func(context.Context, Querier) (Querier, error) { return nil, nil }}, ErrNoQuery},
+	}
+	for _, c := range cases {
+		calls := []string{}
+		_, err := Hooks{c.h, trace{name: "next", calls: &calls}}.Before(context.Background(), Sq{S: "SELECT 1"})
+		if err != c.want || len(calls) > 0 {
+			t.Errorf("%s: got %v and calls %q, want %v", c.name, err, calls, c.want)
+		}
+	}
+}
+
+func TestHookFuncs(t *testing.T) {
+	q, err := HookFuncs{}.Before(context.Background(), Sq{S: "SELECT 1"})
+	if err != nil || q.SQL() != "SELECT 1" {
+		t.Errorf("got %v %v, want the query unchanged", q, err)
+	}
+	HookFuncs{}.After(context.Background(), QueryEvent{})
+
+	var got QueryEvent
+	HookFuncs{AfterFunc: func(_ context.Context, e QueryEvent) { got = e }}.After(context.Background(), QueryEvent{Rows: 3})
+	if got.Rows != 3 {
+		t.Errorf("got event %+v", got)
+	}
+}
+
+func TestHooksGenerate(t *testing.T) {
+	calls := []string{}
+	hs := Hooks{
+		trace{name: "plain", calls: &calls},
+		HookFuncs{GenerateFunc: func(q Querier) (Querier, error) { return Sq{S: q.SQL() + " /*x*/"}, nil }},
+		HookFuncs{},
+	}
+	q, err := hs.Generate(Sq{S: "SELECT 1"})
+	if err != nil || q.SQL() != "SELECT 1 /*x*/" || len(calls) > 0 {
+		t.Errorf("got %v %v and calls %q, want the rewritten query", q, err, calls)
+	}
+	none := HookFuncs{GenerateFunc: func(Querier) (Querier, error) { return nil, nil }}
+	if _, err := (Hooks{none}).Generate(Sq{S: "SELECT 1"}); err != ErrNoQuery {
+		t.Errorf("got %v, want ErrNoQuery", err)
+	}
+}
+
+func TestSharedHooks(t *testing.T) {
+	defer ResetHooks()
+	calls := []string{}
+	UseHooks(trace{name: "a", calls: &calls})
+	UseHooks(trace{name: "b", calls: &calls})
+	hs := SharedHooks(trace{name: "own", calls: &calls})
+	hs.Before(context.Background(), Sq{S: "SELECT 1"})
+	want := []string{"before a SELECT 1", "before b SELECT 1", "before own SELECT 1"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got calls %q, want %q", calls, want)
+	}
+	ResetHooks()
+	if hs := SharedHooks(); len(hs) > 0 {
+		t.Errorf("got %d hooks after ResetHooks", len(hs))
+	}
+}
@@ -2,40 +2,92 @@ package op
 
 import (
 	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
 	"strings"
 )
 
-//Q encases a Namer object in quotes
+// Quoter quotes table and column names for a Dialect,
+// the zero value quotes for dialect.Postgres
+type Quoter struct {
+	d tab.Dialect
+}
+
+// For returns a Quoter for d
+func For(d tab.Dialect) Quoter {
+	return Quoter{d}
+}
+
+// std quotes for the package level functions
+var std = Quoter{}
+
+//Q encases a Namer object in quotes, a Schemer is qualified with its schema
 func Q(n tab.Namer) string {
-	return tab.NameQuotes + n.Name() + tab.NameQuotes
+	return std.Q(n)
 }
 
 //D returns a Column as table.column
 func D(c tab.Column) string {
-	return Q(c.Table()) + "." + Q(c)
+	return std.D(c)
 }
 
 // Join returns a comma separated list of quoted column names
 func Join(cols ...tab.Column) string {
-	flatCols := Columns(cols...)
-	return strings.Join(flatCols, ",")
+	return std.Join(cols...)
 }
 
 // Columns returns an array with column names
 func Columns(cols ...tab.Column) []string {
-	return columns(false, cols...)
+	return std.Columns(cols...)
 }
 
 // QualifiedColumns returns an array with column names, qualified with their table name
 func QualifiedColumns(cols ...tab.Column) []string {
-	return columns(true, cols...)
+	return std.QualifiedColumns(cols...)
+}
+
+// Dialect returns the Quoter's Dialect
+func (q Quoter) Dialect() tab.Dialect {
+	if q.d == nil {
+		return dialect.Postgres
+	}
+	return q.d
+}
+
+//Q encases a Namer object in quotes, a Schemer is qualified with its schema
+func (q Quoter) Q(n tab.Namer) string {
+	name := q.Dialect().QuoteIdent(n.Name())
+	if s, ok := n.(tab.Schemer); ok && len(s.Schema()) > 0 {
+		return q.Dialect().QuoteIdent(s.Schema()) + "." + name
+	}
+	return name
+}
+
+//D returns a Column as table.column
+func (q Quoter) D(c tab.Column) string {
+	return q.Q(c.Table()) + "." + q.Q(c)
+}
+
+// Join returns a comma separated list of quoted column names
+func (q Quoter) Join(cols ...tab.Column) string {
+	flatCols := q.Columns(cols...)
+	return strings.Join(flatCols, ",")
+}
+
+// Columns returns an array with column names
+func (q Quoter) Columns(cols ...tab.Column) []string {
+	return q.columns(false, cols...)
+}
+
+// QualifiedColumns returns an array with column names, qualified with their table name
+func (q Quoter) QualifiedColumns(cols ...tab.Column) []string {
+	return q.columns(true, cols...)
 }
 
-func columns(withTableName bool, cols ...tab.Column) []string {
+func (q Quoter) columns(withTableName bool, cols ...tab.Column) []string {
 	flatCols := []string{}
 	for _, c := range cols {
-		tname := Q(c.Table())
-		cname := Q(c)
+		tname := q.Q(c.Table())
+		cname := q.Q(c)
 		n := cname
 		if withTableName {
 			n = tname + "." + cname
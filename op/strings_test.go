@@ -0,0 +1,78 @@
+package op
+
+import (
+	"reflect"
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+)
+
+type plain struct{}
+
+func (plain) Name() string                  { return "plain" }
+func (plain) Constraints() []tab.Constraint { return nil }
+func (plain) Columns() []tab.Column         { return []tab.Column{plainID{}} }
+
+type plainID struct{}
+
+func (plainID) Name() string     { return "id" }
+func (plainID) Table() tab.Table { return plain{} }
+func (plainID) SQLType() string  { return "int8" }
+func (plainID) NonNull() bool    { return true }
+
+type users struct{}
+
+func (users) Name() string                  { return "users" }
+func (users) Schema() string                { return "auth" }
+func (users) Constraints() []tab.Constraint { return nil }
+func (users) Columns() []tab.Column         { return []tab.Column{userID{}, userEmail{}} }
+
+type userID struct{}
+
+func (userID) Name() string     { return "id" }
+func (userID) Table() tab.Table { return users{} }
+func (userID) SQLType() string  { return "int8" }
+func (userID) NonNull() bool    { return true }
+
+type userEmail struct{}
+
+func (userEmail) Name() string     { return "email" }
+func (userEmail) Table() tab.Table { return users{} }
+func (userEmail) SQLType() string  { return "text" }
+func (userEmail) NonNull() bool    { return true }
+
+func TestQ(t *testing.T) {
+	cases := []struct {
+		n    tab.Namer
+		want string
+	}{
+		{plain{}, `"plain"`},
+		{users{}, `"auth"."users"`},
+		{userID{}, `"id"`},
+	}
+	for _, c := range cases {
+		if got := Q(c.n); got != c.want {
+			t.Errorf("Q(%s): got %s, want %s", c.n.Name(), got, c.want)
+		}
+	}
+	if got := For(dialect.MySQL).Q(users{}); got != "`auth`.`users`" {
+		t.Errorf("mysql Q: got %s", got)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	if got := D(userEmail{}); got != `"auth"."users"."email"` {
+		t.Errorf("D: got %s", got)
+	}
+	if got := D(plainID{}); got != `"plain"."id"` {
+		t.Errorf("D: got %s", got)
+	}
+	if got := Join(userID{}, userEmail{}); got != `"id","email"` {
+		t.Errorf("Join: got %s", got)
+	}
+	want := []string{`"auth"."users"."id"`, `"plain"."id"`}
+	if got := QualifiedColumns(userID{}, plainID{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("QualifiedColumns: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,250 @@
+package pred
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/op"
+)
+
+// Predicate is an SQL boolean expression over typed columns.
+// It renders with ? placeholders and satisfies sqrl.Sqlizer, so any
+// sqrl Where or Having clause can take it and apply its own placeholder format.
+type Predicate interface {
+	// ToSql renders the predicate with dialect.Postgres names
+	ToSql() (string, []interface{}, error)
+	// Render renders the predicate with names quoted for d
+	Render(d tab.Dialect) (string, []interface{}, error)
+}
+
+// Eq matches rows where c equals the value of c, or IS NULL when c holds no value
+func Eq(c tab.Column) Predicate {
+	if isNull(c) {
+		return IsNull(c)
+	}
+	return cmp{c, "="}
+}
+
+// Neq matches rows where c differs from the value of c, or IS NOT NULL when c holds no value
+func Neq(c tab.Column) Predicate {
+	if isNull(c) {
+		return IsNotNull(c)
+	}
+	return cmp{c, "<>"}
+}
+
+// Lt matches rows where c is less than the value of c
+func Lt(c tab.Column) Predicate { return cmp{c, "<"} }
+
+// Lte matches rows where c is less than or equal to the value of c
+func Lte(c tab.Column) Predicate { return cmp{c, "<="} }
+
+// Gt matches rows where c is greater than the value of c
+func Gt(c tab.Column) Predicate { return cmp{c, ">"} }
+
+// Gte matches rows where c is greater than or equal to the value of c
+func Gte(c tab.Column) Predicate { return cmp{c, ">="} }
+
+// Between matches rows where the column is between the values of from and to, inclusive
+func Between(from, to tab.Column) Predicate { return between{from, to} }
+
+// In matches rows where the column equals any of the values in cols
+func In(cols ...tab.Column) Predicate { return in{cols, false} }
+
+// NotIn matches rows where the column equals none of the values in cols
+func NotIn(cols ...tab.Column) Predicate { return in{cols, true} }
+
+// Like matches rows where c matches the pattern held by c
+func Like(c tab.Column) Predicate { return cmp{c, "LIKE"} }
+
+// ILike matches rows where c matches the pattern held by c, ignoring case
+func ILike(c tab.Column) Predicate { return cmp{c, "ILIKE"} }
+
+// IsNull matches rows where c IS NULL, the value of c is ignored
+func IsNull(c tab.Column) Predicate { return null{c, false} }
+
+// IsNotNull matches rows where c IS NOT NULL, the value of c is ignored
+func IsNotNull(c tab.Column) Predicate { return null{c, true} }
+
+// And matches rows satisfying every predicate in ps
+func And(ps ...Predicate) Predicate { return group{ps, "AND"} }
+
+// Or matches rows satisfying any predicate in ps
+func Or(ps ...Predicate) Predicate { return group{ps, "OR"} }
+
+// Not negates p
+func Not(p Predicate) Predicate { return not{p} }
+
+// Empty reports if p has no condition on any column: nil, an In or NotIn
+// without values, an And or Or grouping only empty predicates, or the
+// negation of one. Such a predicate
+// renders as a constant, so a WHERE of it doesn't narrow the rows by itself
+func Empty(p Predicate) bool {
+	switch p := p.(type) {
+	case nil:
+		return true
+	case group:
+		for _, sub := range p.ps {
+			if !Empty(sub) {
+				return false
+			}
+		}
+		return true
+	case in:
+		return len(p.cols) < 1
+	case not:
+		return Empty(p.p)
+	}
+	return false
+}
+
+// Eqs returns the conjunction of Eq for every column in cols,
+// the same filter crud builds from its condition columns
+func Eqs(cols ...tab.Column) Predicate {
+	ps := make([]Predicate, 0, len(cols))
+	for _, c := range cols {
+		ps = append(ps, Eq(c))
+	}
+	return And(ps...)
+}
+
+type cmp struct {
+	col tab.Column
+	op  string
+}
+
+func (p cmp) ToSql() (string, []interface{}, error) {
+	return p.Render(dialect.Postgres)
+}
+
+func (p cmp) Render(d tab.Dialect) (string, []interface{}, error) {
+	return op.For(d).Q(p.col) + " " + p.op + " ?", []interface{}{p.col}, nil
+}
+
+type between struct {
+	from, to tab.Column
+}
+
+func (p between) ToSql() (string, []interface{}, error) {
+	return p.Render(dialect.Postgres)
+}
+
+func (p between) Render(d tab.Dialect) (string, []interface{}, error) {
+	if p.from.Name() != p.to.Name() {
+		return "", nil, tab.QueryGenerationError{Message: "BETWEEN bounds are different columns: " + p.from.Name() + ", " + p.to.Name()}
+	}
+	return op.For(d).Q(p.from) + " BETWEEN ? AND ?", []interface{}{p.from, p.to}, nil
+}
+
+type in struct {
+	cols []tab.Column
+	not  bool
+}
+
+func (p in) ToSql() (string, []interface{}, error) {
+	return p.Render(dialect.Postgres)
+}
+
+func (p in) Render(d tab.Dialect) (string, []interface{}, error) {
+	if len(p.cols) < 1 {
+		// an empty list matches nothing, and its negation everything
+		if p.not {
+			return "(1=1)", nil, nil
+		}
+		return "(1=0)", nil, nil
+	}
+	name := p.cols[0].Name()
+	marks := make([]string, 0, len(p.cols))
+	args := make([]interface{}, 0, len(p.cols))
+	for _, c := range p.cols {
+		if c.Name() != name {
+			return "", nil, tab.QueryGenerationError{Message: "IN list mixes columns: " + name + ", " + c.Name()}
+		}
+		marks = append(marks, "?")
+		args = append(args, c)
+	}
+	o := " IN ("
+	if p.not {
+		o = " NOT IN ("
+	}
+	return op.For(d).Q(p.cols[0]) + o + strings.Join(marks, ",") + ")", args, nil
+}
+
+type null struct {
+	col tab.Column
+	not bool
+}
+
+func (p null) ToSql() (string, []interface{}, error) {
+	return p.Render(dialect.Postgres)
+}
+
+func (p null) Render(d tab.Dialect) (string, []interface{}, error) {
+	if p.not {
+		return op.For(d).Q(p.col) + " IS NOT NULL", nil, nil
+	}
+	return op.For(d).Q(p.col) + " IS NULL", nil, nil
+}
+
+type group struct {
+	ps []Predicate
+	op string
+}
+
+func (p group) ToSql() (string, []interface{}, error) {
+	return p.Render(dialect.Postgres)
+}
+
+func (p group) Render(d tab.Dialect) (string, []interface{}, error) {
+	if len(p.ps) < 1 {
+		// empty conjunction is true, empty disjunction is false
+		if p.op == "AND" {
+			return "(1=1)", nil, nil
+		}
+		return "(1=0)", nil, nil
+	}
+	parts := make([]string, 0, len(p.ps))
+	var args []interface{}
+	for _, sub := range p.ps {
+		if sub == nil {
+			return "", nil, tab.QueryGenerationError{Message: "nil predicate in " + p.op + " group"}
+		}
+		s, a, err := sub.Render(d)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, s)
+		args = append(args, a...)
+	}
+	return "(" + strings.Join(parts, " "+p.op+" ") + ")", args, nil
+}
+
+type not struct {
+	p Predicate
+}
+
+func (p not) ToSql() (string, []interface{}, error) {
+	return p.Render(dialect.Postgres)
+}
+
+func (p not) Render(d tab.Dialect) (string, []interface{}, error) {
+	if p.p == nil {
+		return "", nil, tab.QueryGenerationError{Message: "nil predicate in NOT"}
+	}
+	s, args, err := p.p.Render(d)
+	if err != nil {
+		return "", nil, err
+	}
+	return "NOT (" + s + ")", args, nil
+}
+
+func isNull(c tab.Column) bool {
+	v, ok := c.(driver.Valuer)
+	if !ok {
+		return false
+	}
+	val, err := v.Value()
+	return err == nil && val == nil
+}
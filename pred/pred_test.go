@@ -0,0 +1,130 @@
+package pred
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+)
+
+type users struct{}
+
+func (users) Name() string                  { return "users" }
+func (users) Constraints() []tab.Constraint { return nil }
+func (users) Columns() []tab.Column         { return []tab.Column{id(0), email(nil)} }
+
+type id int64
+
+func (id) Name() string                   { return "id" }
+func (id) Table() tab.Table               { return users{} }
+func (id) SQLType() string                { return "int8" }
+func (id) NonNull() bool                  { return true }
+func (c id) Value() (driver.Value, error) { return int64(c), nil }
+
+type email []byte
+
+func (email) Name() string     { return "email" }
+func (email) Table() tab.Table { return users{} }
+func (email) SQLType() string  { return "text" }
+func (email) NonNull() bool    { return false }
+func (c email) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return string(c), nil
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name string
+		p    Predicate
+		sql  string
+		args []interface{}
+	}{
+		{"eq", Eq(id(1)), `"id" = ?`, []interface{}{id(1)}},
+		{"eq null", Eq(email(nil)), `"email" IS NULL`, nil},
+		{"neq", Neq(id(1)), `"id" <> ?`, []interface{}{id(1)}},
+		{"neq null", Neq(email(nil)), `"email" IS NOT NULL`, nil},
+		{"lt", Lt(id(1)), `"id" < ?`, []interface{}{id(1)}},
+		{"lte", Lte(id(1)), `"id" <= ?`, []interface{}{id(1)}},
+		{"gt", Gt(id(1)), `"id" > ?`, []interface{}{id(1)}},
+		{"gte", Gte(id(1)), `"id" >= ?`, []interface{}{id(1)}},
+		{"like", Like(email("a%")), `"email" LIKE ?`, []interface{}{email("a%")}},
+		{"ilike", ILike(email("a%")), `"email" ILIKE ?`, []interface{}{email("a%")}},
+		{"between", Between(id(1), id(9)), `"id" BETWEEN ? AND ?`, []interface{}{id(1), id(9)}},
+		{"in", In(id(1), id(2)), `"id" IN (?,?)`, []interface{}{id(1), id(2)}},
+		{"not in", NotIn(id(1)), `"id" NOT IN (?)`, []interface{}{id(1)}},
+		{"empty in", In(), `(1=0)`, nil},
+		{"empty not in", NotIn(), `(1=1)`, nil},
+		{"and", And(Eq(id(1)), IsNotNull(email(nil))), `("id" = ? AND "email" IS NOT NULL)`, []interface{}{id(1)}},
+		{"or", Or(Lt(id(1)), Gt(id(9))), `("id" < ? OR "id" > ?)`, []interface{}{id(1), id(9)}},
+		{"empty and", And(), `(1=1)`, nil},
+		{"empty or", Or(), `(1=0)`, nil},
+		{"not", Not(Eq(id(1))), `NOT ("id" = ?)`, []interface{}{id(1)}},
+		{"eqs", Eqs(id(1), email("a")), `("id" = ? AND "email" = ?)`, []interface{}{id(1), email("a")}},
+	}
+	for _, c := range cases {
+		sql, args, err := c.p.ToSql()
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if sql != c.sql || !reflect.DeepEqual(args, c.args) {
+			t.Errorf("%s: got %q %v, want %q %v", c.name, sql, args, c.sql, c.args)
+		}
+	}
+}
+
+func TestRenderDialect(t *testing.T) {
+	sql, _, err := And(Eq(id(1)), IsNull(email(nil))).Render(dialect.MySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(`id` = ? AND `email` IS NULL)"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		p    Predicate
+	}{
+		{"between columns", Between(id(1), email("a"))},
+		{"in columns", In(id(1), email("a"))},
+		{"nil in group", And(Eq(id(1)), nil)},
+		{"nil not", Not(nil)},
+	}
+	for _, c := range cases {
+		_, _, err := c.p.ToSql()
+		if _, ok := err.(tab.QueryGenerationError); !ok {
+			t.Errorf("%s: got %v, want a QueryGenerationError", c.name, err)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	cases := []struct {
+		name string
+		p    Predicate
+		want bool
+	}{
+		{"nil", nil, true},
+		{"and", And(), true},
+		{"or", Or(), true},
+		{"nested", And(Or(), And()), true},
+		{"not", Not(Or()), true},
+		{"eqs", Eqs(), true},
+		{"condition", Eq(id(1)), false},
+		{"nested condition", And(Or(), Or(IsNull(email(nil)))), false},
+		{"not in", NotIn(), true},
+		{"in", In(id(1)), false},
+	}
+	for _, c := range cases {
+		if got := Empty(c.p); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
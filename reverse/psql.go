@@ -7,54 +7,59 @@ import (
 
 // SQLFromPsql returns a query to reverse tables to structs
 func SQLFromPsql(f Filter) (string, []interface{}, error) {
-	args := []interface{}{f.Schema}
+	args := []interface{}{}
+	schemaArgs := []string{}
+	for _, s := range f.SchemaList() {
+		args = append(args, s)
+		schemaArgs = append(schemaArgs, "$"+strconv.Itoa(len(args)))
+	}
 	filterArgs := []string{}
 	for _, n := range f.Tables {
-		count := len(args) + 1
-		filterArgs = append(filterArgs, "$"+strconv.Itoa(count))
 		args = append(args, n)
+		filterArgs = append(filterArgs, "$"+strconv.Itoa(len(args)))
 	}
 
 	tableFilter := ""
 	if len(filterArgs) > 0 {
-		tableFilter = "WHERE table_name IN (" + strings.Join(filterArgs, ",") + ")"
+		tableArgs := strings.Join(filterArgs, ",")
+		tableFilter = "AND (c.relname IN (" + tableArgs + ") OR n.nspname || '.' || c.relname IN (" + tableArgs + "))"
 	}
 
 	query := `
 	with
+	tabs as (
+		select c.oid, n.nspname as table_schema, c.relname as table_name from pg_class c
+		join pg_namespace n on n.oid = c.relnamespace
+		where c.relkind in ('r', 'p') and n.nspname IN (` + strings.Join(schemaArgs, ",") + `)
+		` + tableFilter + `
+	),
 	cols as (
-	select attrelid, attnum, json_build_object('table',relname, 'column',attname) as col, attndims as dimension from pg_attribute
-	join pg_class on attrelid = oid
+		select attrelid, attnum, json_build_object('schema',nspname, 'table',relname, 'column',attname) as col from pg_attribute
+		join pg_class c on attrelid = c.oid
+		join pg_namespace n on n.oid = c.relnamespace
 	),
-	loc as (
-		select conrelid as attrelid, unnest(conkey) as attnum, conname, contype from pg_constraint
+	con as (
+		select pc.oid, pc.conrelid, pc.confrelid, pc.conname, pc.contype, pc.conkey, pc.confkey from pg_constraint pc
+		join tabs on tabs.oid = pc.conrelid
+		where pc.contype in ('f', 'p', 'c', 'u')
 	),
 	local_cols as (
-		select conname, contype, json_agg(col) as columns_local from loc
-		join cols using(attrelid, attnum)
-		group by conname, contype
-	),
-	fog as (
-		select confrelid as attrelid, unnest(confkey) as attnum, conname, contype from pg_constraint
+		select con.oid, json_agg(col order by k.ord) as columns_local from con
+		cross join lateral unnest(con.conkey) with ordinality as k(attnum, ord)
+		join cols on cols.attrelid = con.conrelid and cols.attnum = k.attnum
+		group by con.oid
 	),
-	foreign_cols as  (
-		select conname, contype, json_agg(col) as columns_foreign from fog
-		join cols using(attrelid, attnum)
-		group by conname, contype
-	),
-	constrs as (
-		select relname, conname, columns_local, columns_foreign, pc.contype, pg_get_constraintdef(pc.oid) as constraintdef	from pg_constraint pc 
-		join pg_class pt on pc.conrelid = pt.oid
-		left join local_cols using(conname, contype)
-		left join foreign_cols using(conname, contype)
-		join pg_namespace n ON n.oid = pc.connamespace
-		where n.nspname = $1
+	foreign_cols as (
+		select con.oid, json_agg(col order by k.ord) as columns_foreign from con
+		cross join lateral unnest(con.confkey) with ordinality as k(attnum, ord)
+		join cols on cols.attrelid = con.confrelid and cols.attnum = k.attnum
+		group by con.oid
 	),
 	table_constraints as (
-		SELECT relname as table_name,
+		SELECT con.conrelid as oid,
 			json_agg(json_build_object(
 				'name', conname,
-				'definition', constraintdef,
+				'definition', pg_get_constraintdef(con.oid),
 				'type', CASE
 					WHEN contype = 'f' THEN 'FOREIGN KEY'
 					WHEN contype = 'p' THEN 'PRIMARY KEY'
@@ -63,42 +68,60 @@ func SQLFromPsql(f Filter) (string, []interface{}, error) {
 				END ,
 				'columns_local', columns_local,
 				'columns_foreign', columns_foreign
-			)) as table_constraints
-		from constrs
-		GROUP BY table_name
-	),
-	column_comments as (
-		SELECT n.nspname  as table_schema, c.relname As table_name, a.attname As column_name,  d.description as comment
-		FROM pg_class As c
-		INNER JOIN pg_attribute As a ON c.oid = a.attrelid
-		LEFT JOIN pg_namespace n ON n.oid = c.relnamespace
-		LEFT JOIN pg_tablespace t ON t.oid = c.reltablespace
-		LEFT JOIN pg_description As d ON (d.objoid = c.oid AND d.objsubid = a.attnum)
-		WHERE  c.relkind IN('r', 'v') AND d.description is not null AND n.nspname = $1
-		ORDER BY n.nspname, c.relname, a.attname
+			) ORDER BY conname) as table_constraints
+		from con
+		left join local_cols using(oid)
+		left join foreign_cols using(oid)
+		GROUP BY con.conrelid
 	),
-	table_comments as (
-		select * from (select table_name, (select obj_description(TABLE_NAME::regclass)) as comment from table_constraints) ok
-		where comment is not null
+	idx as (
+		select i.indrelid as oid, json_agg(json_build_object(
+				'name', ic.relname,
+				'method', am.amname,
+				'unique', i.indisunique,
+				'primary', i.indisprimary,
+				'definition', pg_get_indexdef(i.indexrelid),
+				'where', pg_get_expr(i.indpred, i.indrelid),
+				'keys', ks.keys,
+				'include', ks.include
+			) ORDER BY ic.relname) as indexes
+		from pg_index i
+		join tabs on tabs.oid = i.indrelid
+		join pg_class ic on ic.oid = i.indexrelid
+		join pg_am am on am.oid = ic.relam
+		cross join lateral (
+			select
+				json_agg(json_build_object(
+					'column', a.attname,
+					'expression', CASE WHEN k.attnum = 0 THEN pg_get_indexdef(i.indexrelid, k.ord::int, true) END
+				) ORDER BY k.ord) filter (where k.ord <= i.indnkeyatts) as keys,
+				json_agg(a.attname ORDER BY k.ord) filter (where k.ord > i.indnkeyatts) as include
+			from unnest(i.indkey::int2[]) with ordinality as k(attnum, ord)
+			left join pg_attribute a on a.attrelid = i.indrelid and a.attnum = k.attnum
+		) ks
+		group by i.indrelid
 	),
 	columns_list AS (
 		SELECT
-			table_name, json_agg(json_build_object('name', COLUMN_NAME, 'udt_name', udt_name, 'non_null', NOT CAST (is_nullable AS BOOLEAN), 'data_type', data_type,'comment',cc.comment, 'dimension', dimension )) as columns
-		FROM
-		INFORMATION_SCHEMA. COLUMNS incol
-		LEFT JOIN column_comments cc using(TABLE_SCHEMA, table_name, COLUMN_NAME)
-		LEFT JOIN cols ON cols.col->>'column' = incol.COLUMN_NAME and cols.col->>'table' = incol.TABLE_NAME
-		WHERE  TABLE_SCHEMA = 'public'
-		GROUP BY table_name
+			tabs.oid, json_agg(json_build_object('name', COLUMN_NAME, 'position', ordinal_position, 'udt_name', udt_name, 'non_null', NOT CAST (is_nullable AS BOOLEAN), 'data_type', data_type,'comment',col_description(tabs.oid, a.attnum), 'dimension', a.attndims,
+				'length', character_maximum_length,
+				'precision', CASE WHEN data_type = 'numeric' THEN numeric_precision END,
+				'scale', CASE WHEN data_type = 'numeric' THEN numeric_scale END,
+				'enum', (SELECT json_agg(e.enumlabel ORDER BY e.enumsortorder) FROM pg_enum e WHERE e.enumtypid = a.atttypid)
+			) ORDER BY ordinal_position) as columns
+		FROM tabs
+		JOIN INFORMATION_SCHEMA.COLUMNS incol ON incol.TABLE_SCHEMA = tabs.table_schema AND incol.TABLE_NAME = tabs.table_name
+		JOIN pg_attribute a ON a.attrelid = tabs.oid AND a.attname = incol.COLUMN_NAME
+		GROUP BY tabs.oid
 	),
 	all_tables as (
-		select json_build_object('name',table_name, 'columns',columns, 'constraints',table_constraints, 'comment',comment) as table from table_constraints
-		left join columns_list using(table_name)
-		left join table_comments using(table_name)
-		` + tableFilter + `
+		select table_schema, table_name, json_build_object('name',table_name, 'schema',table_schema, 'columns',columns, 'constraints',table_constraints, 'indexes',indexes, 'comment',obj_description(oid, 'pg_class')) as table from tabs
+		left join columns_list using(oid)
+		left join table_constraints using(oid)
+		left join idx using(oid)
 	)
 
-	select json_agg(all_tables.table) as tables from all_tables
+	select json_agg(all_tables.table ORDER BY table_schema, table_name) as tables from all_tables
 		`
 
 	return query, args, nil
@@ -3,6 +3,9 @@ package reverse
 import (
 	"encoding/json"
 	"errors"
+	"sort"
+	"strings"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/types"
 )
@@ -10,19 +13,39 @@ import (
 // Table represents a database table
 type Table struct {
 	Name        string       `json:"name"`
+	Schema      string       `json:"schema"`
 	Columns     []Column     `json:"columns"`
 	Constraints []Constraint `json:"constraints"`
+	Indexes     []Index      `json:"indexes"`
 	Comment     *string      `json:"comment"`
 }
 
+// Column returns the column of t called name
+func (t Table) Column(name string) (Column, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
 // Column represents a database column
 type Column struct {
 	Name      string  `json:"name"`
+	Position  int     `json:"position"`
 	UDTName   string  `json:"udt_name"`
 	NonNull   bool    `json:"non_null"`
 	DataType  string  `json:"data_type"`
 	Comment   *string `json:"comment"`
 	Dimension int32   `json:"dimension"`
+	// Length is the limit of a varchar(n) or char(n)
+	Length *int `json:"length"`
+	// Precision and Scale are the digits of a numeric(p,s), nil when unconstrained
+	Precision *int `json:"precision"`
+	Scale     *int `json:"scale"`
+	// Enum holds the labels of an enum type, in order
+	Enum []string `json:"enum"`
 }
 
 // Constraint represents a database constraint
@@ -34,18 +57,71 @@ type Constraint struct {
 	ColumnsForeign []ConstraintColumn `json:"columns_foreign"`
 }
 
+// Index represents a database index, including those of unique and primary key constraints
+type Index struct {
+	Name       string     `json:"name"`
+	Method     string     `json:"method"`
+	Unique     bool       `json:"unique"`
+	Primary    bool       `json:"primary"`
+	Definition string     `json:"definition"`
+	Where      *string    `json:"where"`
+	Keys       []IndexKey `json:"keys"`
+	Include    []string   `json:"include"`
+}
+
+// IndexKey is an indexed column, or an expression when Column is nil
+type IndexKey struct {
+	Column     *string `json:"column"`
+	Expression *string `json:"expression"`
+}
+
 // ConstraintColumn represents a database column constraint definition
 type ConstraintColumn struct {
+	Schema string `json:"schema"`
 	Table  string `json:"table"`
 	Column string `json:"column"`
 }
 
-// Filter holds schema and tables to filter results by
+// Filter holds schemas and tables to filter results by
 type Filter struct {
-	Schema string
+	Schema  string
+	Schemas []string
+	// Tables are table names, or schema.table to pick one among schemas
 	Tables []string
 }
 
+// SchemaList returns Schema and Schemas without duplicates, "public" if both are empty
+func (f Filter) SchemaList() []string {
+	seen := map[string]bool{}
+	l := []string{}
+	for _, s := range append([]string{f.Schema}, f.Schemas...) {
+		if len(s) > 0 && !seen[s] {
+			seen[s] = true
+			l = append(l, s)
+		}
+	}
+	if len(l) < 1 {
+		l = append(l, "public")
+	}
+	return l
+}
+
+// missing returns the requested tables not in t
+func (f Filter) missing(t []Table) []string {
+	found := map[string]bool{}
+	for _, tb := range t {
+		found[tb.Name] = true
+		found[tb.Schema+"."+tb.Name] = true
+	}
+	m := []string{}
+	for _, n := range f.Tables {
+		if !found[n] {
+			m = append(m, n)
+		}
+	}
+	return m
+}
+
 // SQLGenerator generates an SQL query to reverse database -> structs
 type SQLGenerator func(Filter) (string, []interface{}, error)
 
@@ -56,6 +132,8 @@ type Reverser struct {
 }
 
 // Run starts the reversing process
+// Requested tables that don't exist are reported in a MissingTablesErr,
+// returned along with the tables that were found
 func (r *Reverser) Run(f Filter) (t []Table, err error) {
 	qSQL, args, err := r.GetSQL(f)
 	if err != nil {
@@ -69,10 +147,45 @@ func (r *Reverser) Run(f Filter) (t []Table, err error) {
 		return nil, err
 	}
 	if res.Tables == nil {
+		if len(f.Tables) > 0 {
+			return nil, MissingTablesErr{Tables: f.Tables}
+		}
 		return nil, NoTablesErr(errors.New("No result from query"))
 	}
 	err = json.Unmarshal((*res.Tables), &t)
-	return t, err
+	if err != nil {
+		return nil, err
+	}
+	Sort(t)
+	if m := f.missing(t); len(m) > 0 {
+		return t, MissingTablesErr{Tables: m}
+	}
+	return t, nil
+}
+
+// Sort orders tables by schema and name, their columns by position
+// and constraints and indexes by name, so an unchanged database always reverses the same
+func Sort(t []Table) {
+	sort.SliceStable(t, func(i, j int) bool {
+		if t[i].Schema != t[j].Schema {
+			return t[i].Schema < t[j].Schema
+		}
+		return t[i].Name < t[j].Name
+	})
+	for _, tb := range t {
+		cols := tb.Columns
+		sort.SliceStable(cols, func(i, j int) bool {
+			return cols[i].Position < cols[j].Position
+		})
+		cs := tb.Constraints
+		sort.SliceStable(cs, func(i, j int) bool {
+			return cs[i].Name < cs[j].Name
+		})
+		idx := tb.Indexes
+		sort.SliceStable(idx, func(i, j int) bool {
+			return idx[i].Name < idx[j].Name
+		})
+	}
 }
 
 // New creates a new Reverser
@@ -82,3 +195,12 @@ func New(db *sqlx.DB, r SQLGenerator) (*Reverser, error) {
 
 // NoTablesErr is the error returned when no results return from the query
 type NoTablesErr error
+
+// MissingTablesErr is the error returned when requested tables were not found
+type MissingTablesErr struct {
+	Tables []string
+}
+
+func (e MissingTablesErr) Error() string {
+	return "tables not found: " + strings.Join(e.Tables, ", ")
+}
@@ -0,0 +1,207 @@
+package reverse
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// catalog is a driver answering every query with one tables row holding its JSON
+type catalog struct {
+	json  []byte
+	query string
+	args  []driver.Value
+}
+
+func (c *catalog) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *catalog) Driver() driver.Driver                        { return nil }
+func (c *catalog) Prepare(query string) (driver.Stmt, error)    { return &catalogStmt{c, query}, nil }
+func (c *catalog) Close() error                                 { return nil }
+func (c *catalog) Begin() (driver.Tx, error)                    { return nil, errors.New("no transactions") }
+
+type catalogStmt struct {
+	c     *catalog
+	query string
+}
+
+func (s *catalogStmt) Close() error  { return nil }
+func (s *catalogStmt) NumInput() int { return -1 }
+func (s *catalogStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("no exec")
+}
+func (s *catalogStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.query, s.c.args = s.query, args
+	return &catalogRows{json: s.c.json}, nil
+}
+
+type catalogRows struct {
+	json []byte
+	done bool
+}
+
+func (r *catalogRows) Columns() []string { return []string{"tables"} }
+func (r *catalogRows) Close() error      { return nil }
+func (r *catalogRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	if r.json == nil {
+		dest[0] = nil
+	} else {
+		dest[0] = r.json
+	}
+	return nil
+}
+
+func reverser(c *catalog) *Reverser {
+	r, _ := New(sqlx.NewDb(sql.OpenDB(c), "postgres"), SQLFromPsql)
+	return r
+}
+
+func TestSchemaList(t *testing.T) {
+	cases := []struct {
+		f    Filter
+		want []string
+	}{
+		{Filter{}, []string{"public"}},
+		{Filter{Schema: "app"}, []string{"app"}},
+		{Filter{Schema: "app", Schemas: []string{"auth", "app", ""}}, []string{"app", "auth"}},
+	}
+	for _, c := range cases {
+		if got := c.f.SchemaList(); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%+v: got %v, want %v", c.f, got, c.want)
+		}
+	}
+}
+
+func TestSQLFromPsql(t *testing.T) {
+	sql, args, err := SQLFromPsql(Filter{Schemas: []string{"app", "auth"}, Tables: []string{"users", "auth.roles"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"app", "auth", "users", "auth.roles"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("got args %v, want %v", args, want)
+	}
+	for _, w := range []string{
+		"n.nspname IN ($1,$2)",
+		"c.relname IN ($3,$4) OR n.nspname || '.' || c.relname IN ($3,$4)",
+	} {
+		if !strings.Contains(sql, w) {
+			t.Errorf("query is missing %q", w)
+		}
+	}
+
+	sql, args, _ = SQLFromPsql(Filter{})
+	if !reflect.DeepEqual(args, []interface{}{"public"}) || strings.Contains(sql, "c.relname IN") {
+		t.Errorf("unfiltered query: got args %v\n%s", args, sql)
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := &catalog{json: []byte(`[
+		{"name": "users", "schema": "app", "columns": [{"name": "id", "position": 1}]},
+		{"name": "roles", "schema": "auth", "columns": null}
+	]`)}
+	tables, err := reverser(c).Run(Filter{Schemas: []string{"app", "auth"}, Tables: []string{"users", "auth.roles", "app.gone"}})
+	missing, ok := err.(MissingTablesErr)
+	if !ok || !reflect.DeepEqual(missing.Tables, []string{"app.gone"}) {
+		t.Fatalf("got %v, want the missing app.gone", err)
+	}
+	if len(tables) != 2 || tables[0].Schema != "app" || tables[1].Name != "roles" {
+		t.Errorf("got tables %+v", tables)
+	}
+	if want := []driver.Value{"app", "auth", "users", "auth.roles", "app.gone"}; !reflect.DeepEqual(c.args, want) {
+		t.Errorf("got args %v, want %v", c.args, want)
+	}
+}
+
+func TestRunNoTables(t *testing.T) {
+	_, err := reverser(&catalog{}).Run(Filter{Tables: []string{"users"}})
+	if missing, ok := err.(MissingTablesErr); !ok || !reflect.DeepEqual(missing.Tables, []string{"users"}) {
+		t.Errorf("got %v, want users missing", err)
+	}
+	_, err = reverser(&catalog{}).Run(Filter{})
+	if err == nil {
+		t.Error("got no error for an empty database")
+	}
+}
+
+func TestSort(t *testing.T) {
+	tables := []Table{
+		{Name: "b", Schema: "app"},
+		{Name: "a", Schema: "auth"},
+		{Name: "a", Schema: "app",
+			Columns:     []Column{{Name: "z", Position: 2}, {Name: "y", Position: 1}},
+			Constraints: []Constraint{{Name: "k2"}, {Name: "k1"}},
+		},
+	}
+	Sort(tables)
+	order := []string{}
+	for _, tb := range tables {
+		order = append(order, tb.Schema+"."+tb.Name)
+	}
+	if want := []string{"app.a", "app.b", "auth.a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("got tables %v, want %v", order, want)
+	}
+	a := tables[0]
+	if a.Columns[0].Name != "y" || a.Constraints[0].Name != "k1" {
+		t.Errorf("got columns %+v, constraints %+v", a.Columns, a.Constraints)
+	}
+}
+
+func TestIndexes(t *testing.T) {
+	sql, _, _ := SQLFromPsql(Filter{})
+	for _, w := range []string{
+		"'where', pg_get_expr(i.indpred, i.indrelid)",
+		"'expression', CASE WHEN k.attnum = 0 THEN pg_get_indexdef(i.indexrelid, k.ord::int, true) END",
+		"filter (where k.ord <= i.indnkeyatts) as keys",
+		"filter (where k.ord > i.indnkeyatts) as include",
+		"from unnest(i.indkey::int2[]) with ordinality as k(attnum, ord)",
+		"left join idx using(oid)",
+		"'indexes',indexes",
+	} {
+		if !strings.Contains(sql, w) {
+			t.Errorf("query is missing %q", w)
+		}
+	}
+
+	// the idx rows of a primary key, a partial expression index and a covering index
+	c := &catalog{json: []byte(`[{"name": "users", "schema": "app", "indexes": [
+		{"name": "users_tenant_idx", "method": "btree", "unique": false, "primary": false,
+			"definition": "CREATE INDEX users_tenant_idx ON app.users USING btree (tenant_id, created_at DESC) INCLUDE (email)",
+			"where": null, "keys": [{"column": "tenant_id", "expression": null}, {"column": "created_at", "expression": null}], "include": ["email"]},
+		{"name": "users_lower_email_idx", "method": "btree", "unique": true, "primary": false,
+			"definition": "CREATE UNIQUE INDEX users_lower_email_idx ON app.users USING btree (lower(email)) WHERE (deleted_at IS NULL)",
+			"where": "(deleted_at IS NULL)", "keys": [{"column": null, "expression": "lower(email)"}], "include": null},
+		{"name": "users_pkey", "method": "btree", "unique": true, "primary": true,
+			"definition": "CREATE UNIQUE INDEX users_pkey ON app.users USING btree (id)",
+			"where": null, "keys": [{"column": "id", "expression": null}], "include": null}
+	]}]`)}
+	tables, err := reverser(c).Run(Filter{Schema: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	str := func(s string) *string { return &s }
+	want := []Index{
+		{Name: "users_lower_email_idx", Method: "btree", Unique: true,
+			Definition: "CREATE UNIQUE INDEX users_lower_email_idx ON app.users USING btree (lower(email)) WHERE (deleted_at IS NULL)",
+			Where:      str("(deleted_at IS NULL)"), Keys: []IndexKey{{Expression: str("lower(email)")}}},
+		{Name: "users_pkey", Method: "btree", Unique: true, Primary: true,
+			Definition: "CREATE UNIQUE INDEX users_pkey ON app.users USING btree (id)",
+			Keys:       []IndexKey{{Column: str("id")}}},
+		{Name: "users_tenant_idx", Method: "btree",
+			Definition: "CREATE INDEX users_tenant_idx ON app.users USING btree (tenant_id, created_at DESC) INCLUDE (email)",
+			Keys:       []IndexKey{{Column: str("tenant_id")}, {Column: str("created_at")}}, Include: []string{"email"}},
+	}
+	if len(tables) != 1 || !reflect.DeepEqual(tables[0].Indexes, want) {
+		t.Errorf("got indexes %+v, want %+v", tables, want)
+	}
+}
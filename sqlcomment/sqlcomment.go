@@ -0,0 +1,150 @@
+// Package sqlcomment tags SQL statements with sqlcommenter comments,
+// key value pairs appended to a statement to trace it back to code
+// See https://google.github.io/sqlcommenter/spec/
+package sqlcomment
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+// Tags are the key value pairs of a comment
+type Tags map[string]string
+
+type tagsKey struct{}
+
+// WithTags returns a copy of ctx carrying tags over the ones it already carries
+func WithTags(ctx context.Context, tags Tags) context.Context {
+	return context.WithValue(ctx, tagsKey{}, FromContext(ctx).Merge(tags))
+}
+
+// FromContext returns the tags carried by ctx
+func FromContext(ctx context.Context) Tags {
+	t, _ := ctx.Value(tagsKey{}).(Tags)
+	return t
+}
+
+// Merge returns a copy of t with the tags of over replacing its own
+func (t Tags) Merge(over Tags) Tags {
+	m := make(Tags, len(t)+len(over))
+	for k, v := range t {
+		m[k] = v
+	}
+	for k, v := range over {
+		m[k] = v
+	}
+	return m
+}
+
+// Only returns a copy of t holding only keys, all of t when keys is empty
+func (t Tags) Only(keys ...string) Tags {
+	if len(keys) < 1 {
+		return t
+	}
+	m := Tags{}
+	for _, k := range keys {
+		if v, ok := t[k]; ok {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+// String renders t as a comment sorted by key, empty when t is
+func (t Tags) String() string {
+	if len(t) < 1 {
+		return ""
+	}
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, escape(k)+"='"+escape(t[k])+"'")
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+// Table returns the table tag of t, qualified by its schema
+func Table(t tab.Table) Tags {
+	name := t.Name()
+	if s, ok := t.(tab.Schemer); ok && len(s.Schema()) > 0 {
+		name = s.Schema() + "." + name
+	}
+	return Tags{"table": name}
+}
+
+// Append appends the comment of tags to sql, merging them into the comment
+// sql already ends with. A statement with any other comment is left as is
+func Append(sql string, tags Tags) string {
+	if len(tags) < 1 {
+		return sql
+	}
+	if rest, prev, ok := trailing(sql); ok {
+		return rest + prev.Merge(tags).String()
+	}
+	if strings.Contains(sql, "/*") || strings.Contains(sql, "--") {
+		return sql
+	}
+	return sql + " " + tags.String()
+}
+
+// Hook is a tabua.Hook appending the tags of a statement's context
+type Hook struct {
+	// Keys limits the tags added, all when empty. Tags changing on every request,
+	// like traceparent, change the SQL too and defeat statement caches
+	Keys []string
+}
+
+// Before implements tabua.Hook
+func (h Hook) Before(ctx context.Context, q tab.Querier) (tab.Querier, error) {
+	tags := FromContext(ctx).Only(h.Keys...)
+	if len(tags) < 1 {
+		return q, nil
+	}
+	return tab.Sq{S: Append(q.SQL(), tags), A: q.Args()}, nil
+}
+
+// After implements tabua.Hook
+func (h Hook) After(ctx context.Context, e tab.QueryEvent) {}
+
+// trailing parses the comment sql ends with, it must hold only key='value' pairs
+func trailing(sql string) (string, Tags, bool) {
+	if !strings.HasSuffix(sql, "*/") {
+		return sql, nil, false
+	}
+	start := strings.LastIndex(sql, "/*")
+	if start < 0 {
+		return sql, nil, false
+	}
+	tags := Tags{}
+	for _, pair := range strings.Split(sql[start+2:len(sql)-2], ",") {
+		eq := strings.Index(pair, "='")
+		if eq < 1 || !strings.HasSuffix(pair, "'") || len(pair) < eq+3 {
+			return sql, nil, false
+		}
+		k, kerr := unescape(pair[:eq])
+		v, verr := unescape(pair[eq+2 : len(pair)-1])
+		if kerr != nil || verr != nil {
+			return sql, nil, false
+		}
+		tags[k] = v
+	}
+	return sql[:start], tags, true
+}
+
+// escape URL encodes s, then escapes the quotes left
+func escape(s string) string {
+	s = strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+	return strings.Replace(s, "'", `\'`, -1)
+}
+
+func unescape(s string) (string, error) {
+	return url.QueryUnescape(strings.Replace(s, `\'`, "'", -1))
+}
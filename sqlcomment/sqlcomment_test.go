@@ -0,0 +1,122 @@
+package sqlcomment
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	tab "github.com/pindamonhangaba/tabua"
+)
+
+type users struct{}
+
+func (users) Name() string                  { return "users" }
+func (users) Schema() string                { return "app" }
+func (users) Constraints() []tab.Constraint { return nil }
+func (users) Columns() []tab.Column         { return nil }
+
+type plain struct{ users }
+
+func (plain) Schema() string { return "" }
+
+func TestAppend(t *testing.T) {
+	cases := []struct {
+		name string
+		sql  string
+		tags Tags
+		want string
+	}{
+		{"no tags", "SELECT 1", nil, "SELECT 1"},
+		{"appended", "SELECT 1", Tags{"route": "/a", "app": "api"}, "SELECT 1 /*app='api',route='%2Fa'*/"},
+		{"escaped", "SELECT 1", Tags{"a b": "it's */ -- x=1,y"}, `SELECT 1 /*a%20b='it%27s%20%2A%2F%20--%20x%3D1%2Cy'*/`},
+		{"merged", "SELECT 1 /*app='api',route='%2Fa'*/", Tags{"route": "/b", "db": "main"}, "SELECT 1 /*app='api',db='main',route='%2Fb'*/"},
+		{"merged escaped", `SELECT 1 /*a%20b='it\'s'*/`, Tags{"c": "d"}, `SELECT 1 /*a%20b='it%27s',c='d'*/`},
+		{"other trailing comment", "SELECT 1 /* by hand */", Tags{"app": "api"}, "SELECT 1 /* by hand */"},
+		{"block comment", "SELECT /* x */ 1", Tags{"app": "api"}, "SELECT /* x */ 1"},
+		{"line comment", "SELECT 1 -- x", Tags{"app": "api"}, "SELECT 1 -- x"},
+	}
+	for _, c := range cases {
+		if got := Append(c.sql, c.tags); got != c.want {
+			t.Errorf("%s: got %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tags := Tags{
+		"route":       "/users/{id}?x=1&y=2",
+		"quote's":     `it's "quoted"`,
+		"comment":     "*/ DROP TABLE users; --",
+		"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"unicode":     "ação, 中文",
+		"empty":       "",
+	}
+	sql, got, ok := trailing(Append("SELECT 1", tags))
+	if !ok || sql != "SELECT 1 " || !reflect.DeepEqual(got, tags) {
+		t.Errorf("got %q %v %v, want %v", sql, got, ok, tags)
+	}
+	for k, v := range tags {
+		for _, s := range []string{k, v} {
+			if u, err := unescape(escape(s)); err != nil || u != s {
+				t.Errorf("%q: got %q %v", s, u, err)
+			}
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	tags := Tags{"app": "api", "route": "/a"}
+	if got := tags.Merge(Tags{"route": "/b"}); !reflect.DeepEqual(got, Tags{"app": "api", "route": "/b"}) || tags["route"] != "/a" {
+		t.Errorf("merge: got %v, %v left", got, tags)
+	}
+	if got := tags.Only("route", "missing"); !reflect.DeepEqual(got, Tags{"route": "/a"}) {
+		t.Errorf("only: got %v", got)
+	}
+	if got := tags.Only(); !reflect.DeepEqual(got, tags) {
+		t.Errorf("only nothing: got %v", got)
+	}
+	if got := (Tags{}).String(); got != "" {
+		t.Errorf("string: got %q for no tags", got)
+	}
+	if got := Table(users{}); !reflect.DeepEqual(got, Tags{"table": "app.users"}) {
+		t.Errorf("table: got %v", got)
+	}
+	if got := Table(plain{}); !reflect.DeepEqual(got, Tags{"table": "users"}) {
+		t.Errorf("table without schema: got %v", got)
+	}
+
+	ctx := WithTags(WithTags(context.Background(), tags), Tags{"route": "/b"})
+	if got := FromContext(ctx); !reflect.DeepEqual(got, Tags{"app": "api", "route": "/b"}) {
+		t.Errorf("context: got %v", got)
+	}
+	if got := FromContext(context.Background()); got != nil {
+		t.Errorf("empty context: got %v", got)
+	}
+}
+
+func TestHook(t *testing.T) {
+	ctx := WithTags(context.Background(), Tags{"app": "api", "traceparent": "00-1"})
+	q := tab.Sq{S: `SELECT 1 WHERE "id" = $1`, A: []interface{}{driver.Value(1)}}
+	cases := []struct {
+		name string
+		ctx  context.Context
+		h    Hook
+		want string
+	}{
+		{"all", ctx, Hook{}, `SELECT 1 WHERE "id" = $1 /*app='api',traceparent='00-1'*/`},
+		{"keys", ctx, Hook{Keys: []string{"app"}}, `SELECT 1 WHERE "id" = $1 /*app='api'*/`},
+		{"no tags", context.Background(), Hook{}, `SELECT 1 WHERE "id" = $1`},
+		{"no keys", ctx, Hook{Keys: []string{"route"}}, `SELECT 1 WHERE "id" = $1`},
+	}
+	for _, c := range cases {
+		got, err := c.h.Before(c.ctx, q)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if got.SQL() != c.want || !reflect.DeepEqual(got.Args(), q.Args()) {
+			t.Errorf("%s: got %s %v, want %s", c.name, got.SQL(), got.Args(), c.want)
+		}
+	}
+}
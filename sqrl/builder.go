@@ -0,0 +1,133 @@
+package sqrl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	sq "github.com/elgris/sqrl"
+	t "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/op"
+	"github.com/pindamonhangaba/tabua/pred"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
+)
+
+// ErrNoRunner is returned when running a builder before RunWith.
+var ErrNoRunner = errors.New("sqrl: no Runner set, call RunWith")
+
+// Runner runs the statements of builders, a *sql.DB, *sql.Tx, *sqlx.DB or *sqlx.Tx.
+type Runner interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// RowScanner scans the row of QueryRow.
+type RowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// StatementBuilder creates builders quoting names and binding arguments for a Dialect.
+type StatementBuilder struct {
+	q    op.Quoter
+	sb   sq.StatementBuilderType
+	tags sqlcomment.Tags
+}
+
+// New returns a StatementBuilder for d.
+func New(d t.Dialect) StatementBuilder {
+	return StatementBuilder{
+		q:  op.For(d),
+		sb: sq.StatementBuilder.PlaceholderFormat(dialect.Format{Dialect: d}),
+	}
+}
+
+// Comment returns a copy of the StatementBuilder tagging statements with a sqlcommenter comment of tags and the table.
+func (s StatementBuilder) Comment(tags sqlcomment.Tags) StatementBuilder {
+	s.tags = s.tags.Merge(tags)
+	return s
+}
+
+// Select returns a SelectBuilder for cols.
+func (s StatementBuilder) Select(cols ...t.Column) *SelectBuilder {
+	b := &SelectBuilder{SelectBuilder: s.sb.Select(), q: s.q, tags: s.tags}
+	return b.Columns(cols...)
+}
+
+// Insert returns an InsertBuilder into table.
+func (s StatementBuilder) Insert(table t.Table) *InsertBuilder {
+	return &InsertBuilder{InsertBuilder: s.sb.Insert(s.q.Q(table)), q: s.q, table: table, tags: s.tags}
+}
+
+// Update returns an UpdateBuilder for table.
+func (s StatementBuilder) Update(table t.Table) *UpdateBuilder {
+	return &UpdateBuilder{UpdateBuilder: s.sb.Update(s.q.Q(table)), q: s.q, table: table, tags: s.tags}
+}
+
+// comment appends tags and the table to sql, when tags are set.
+func comment(sql string, table t.Table, tags sqlcomment.Tags) string {
+	if tags == nil {
+		return sql
+	}
+	if table != nil {
+		tags = sqlcomment.Table(table).Merge(tags)
+	}
+	return sqlcomment.Append(sql, tags)
+}
+
+// sqlizer builds a query, the builders of this package.
+type sqlizer interface {
+	ToSql() (string, []interface{}, error)
+}
+
+// exec builds s and Execs it with r.
+func exec(ctx context.Context, r Runner, s sqlizer) (sql.Result, error) {
+	if r == nil {
+		return nil, ErrNoRunner
+	}
+	stmt, args, err := s.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.ExecContext(ctx, stmt, args...)
+}
+
+// query builds s and returns its rows from r.
+func query(ctx context.Context, r Runner, s sqlizer) (*sql.Rows, error) {
+	if r == nil {
+		return nil, ErrNoRunner
+	}
+	stmt, args, err := s.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return r.QueryContext(ctx, stmt, args...)
+}
+
+// queryRow builds s and returns its row from r, a row failing to scan when it can't.
+func queryRow(ctx context.Context, r Runner, s sqlizer) RowScanner {
+	if r == nil {
+		return errRow{ErrNoRunner}
+	}
+	stmt, args, err := s.ToSql()
+	if err != nil {
+		return errRow{err}
+	}
+	return r.QueryRowContext(ctx, stmt, args...)
+}
+
+// errRow is a row failing to scan with err.
+type errRow struct{ err error }
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// rendered renders a predicate for a dialect.
+type rendered struct {
+	p pred.Predicate
+	d t.Dialect
+}
+
+func (r rendered) ToSql() (string, []interface{}, error) {
+	return r.p.Render(r.d)
+}
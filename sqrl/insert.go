@@ -1,23 +1,89 @@
 package sqrl
 
 import (
+	"context"
+	"database/sql"
+
 	sq "github.com/elgris/sqrl"
 	t "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/op"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
 )
 
 // InsertBuilder builds SQL INSERT statements.
+// Methods of the embedded sqrl builder not wrapped here change it in place and return it,
+// call them without chaining to keep the comment of ToSql and the Runner of RunWith.
 type InsertBuilder struct {
 	*sq.InsertBuilder
+	q      op.Quoter
+	table  t.Table
+	tags   sqlcomment.Tags
+	runner Runner
 }
 
 // Into sets the INTO clause of the query.
 func (b *InsertBuilder) Into(table t.Table) *InsertBuilder {
-	b.InsertBuilder = b.InsertBuilder.Into(t.Q(table))
+	b.InsertBuilder = b.InsertBuilder.Into(b.q.Q(table))
+	b.table = table
 	return b
 }
 
+// ToSql builds the query into a SQL string and bound args.
+func (b *InsertBuilder) ToSql() (string, []interface{}, error) {
+	sql, args, err := b.InsertBuilder.ToSql()
+	return comment(sql, b.table, b.tags), args, err
+}
+
 // Columns adds insert columns to the query.
 func (b *InsertBuilder) Columns(cols ...t.Column) *InsertBuilder {
-	b.InsertBuilder = b.InsertBuilder.Columns(t.Columns(cols...)...)
+	b.InsertBuilder = b.InsertBuilder.Columns(b.q.Columns(cols...)...)
+	return b
+}
+
+// Values adds a row of values to the query.
+func (b *InsertBuilder) Values(values ...interface{}) *InsertBuilder {
+	b.InsertBuilder = b.InsertBuilder.Values(values...)
+	return b
+}
+
+// Suffix adds an expression to the end of the query, such as RETURNING.
+func (b *InsertBuilder) Suffix(sql string, args ...interface{}) *InsertBuilder {
+	b.InsertBuilder = b.InsertBuilder.Suffix(sql, args...)
+	return b
+}
+
+// RunWith sets the Runner the query is run with.
+func (b *InsertBuilder) RunWith(r Runner) *InsertBuilder {
+	b.runner = r
 	return b
 }
+
+// Exec builds and Execs the query with the Runner set by RunWith.
+func (b *InsertBuilder) Exec() (sql.Result, error) {
+	return b.ExecContext(context.Background())
+}
+
+// ExecContext builds and Execs the query with the Runner set by RunWith, bound to ctx.
+func (b *InsertBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	return exec(ctx, b.runner, b)
+}
+
+// Query builds the query and returns its rows from the Runner set by RunWith.
+func (b *InsertBuilder) Query() (*sql.Rows, error) {
+	return b.QueryContext(context.Background())
+}
+
+// QueryContext builds the query and returns its rows from the Runner set by RunWith, bound to ctx.
+func (b *InsertBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	return query(ctx, b.runner, b)
+}
+
+// QueryRow builds the query and returns its row from the Runner set by RunWith.
+func (b *InsertBuilder) QueryRow() RowScanner {
+	return b.QueryRowContext(context.Background())
+}
+
+// QueryRowContext builds the query and returns its row from the Runner set by RunWith, bound to ctx.
+func (b *InsertBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	return queryRow(ctx, b.runner, b)
+}
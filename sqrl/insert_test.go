@@ -0,0 +1,49 @@
+package sqrl
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
+)
+
+func TestInsert(tt *testing.T) {
+	s := New(dialect.Postgres)
+	cases := []struct {
+		name string
+		b    *InsertBuilder
+		sql  string
+		args []interface{}
+	}{
+		{"values", s.Insert(users{}).Columns(userID(0), userEmail("")).Values(userID(1), userEmail("a")),
+			`INSERT INTO "app"."users" ("id","email") VALUES ($1,$2)`, []interface{}{userID(1), userEmail("a")}},
+		{"returning", s.Insert(users{}).Columns(userEmail("")).Values(userEmail("a")).Suffix(`RETURNING "id"`),
+			`INSERT INTO "app"."users" ("email") VALUES ($1) RETURNING "id"`, []interface{}{userEmail("a")}},
+		{"comment", s.Comment(sqlcomment.Tags{"app": "api"}).Insert(users{}).Columns(userEmail("")).Values(userEmail("a")),
+			`INSERT INTO "app"."users" ("email") VALUES ($1) /*app='api',table='app.users'*/`, []interface{}{userEmail("a")}},
+	}
+	for _, c := range cases {
+		sql, args, err := c.b.ToSql()
+		if err != nil {
+			tt.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if sql != c.sql || !reflect.DeepEqual(args, c.args) {
+			tt.Errorf("%s: got %s %v, want %s %v", c.name, sql, args, c.sql, c.args)
+		}
+
+		r := &runner{}
+		b := c.b.RunWith(r)
+		b.ExecContext(context.Background())
+		b.QueryContext(context.Background())
+		b.QueryRowContext(context.Background())
+		if !reflect.DeepEqual(r.queries, []string{c.sql, c.sql, c.sql}) {
+			tt.Errorf("%s: ran %q, want %q", c.name, r.queries, c.sql)
+		}
+	}
+	if _, err := s.Insert(users{}).Columns(userEmail("")).Values(userEmail("a")).Exec(); err != ErrNoRunner {
+		tt.Errorf("got %v without a runner, want ErrNoRunner", err)
+	}
+}
@@ -1,44 +1,62 @@
 package sqrl
 
 import (
+	"context"
+	"database/sql"
 	"strings"
 
 	sq "github.com/elgris/sqrl"
 	t "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/op"
+	"github.com/pindamonhangaba/tabua/pred"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
 )
 
 // SelectBuilder builds SQL SELECT statements.
+// Methods of the embedded sqrl builder not wrapped here, such as Distinct or Having, change it in place
+// and return it, call them without chaining to keep the comment of ToSql and the Runner of RunWith.
 type SelectBuilder struct {
 	*sq.SelectBuilder
+	q      op.Quoter
+	table  t.Table
+	tags   sqlcomment.Tags
+	runner Runner
 }
 
 // Columns adds result columns to the query.
 func (b *SelectBuilder) Columns(cols ...t.Column) *SelectBuilder {
-	b.SelectBuilder = b.SelectBuilder.Columns(t.Columns(cols...)...)
+	b.SelectBuilder = b.SelectBuilder.Columns(b.q.Columns(cols...)...)
 	return b
 }
 
 // From sets the FROM clause of the query.
 func (b *SelectBuilder) From(table t.Table) *SelectBuilder {
-	b.SelectBuilder = b.SelectBuilder.From(t.Q(table))
+	b.SelectBuilder = b.SelectBuilder.From(b.q.Q(table))
+	b.table = table
 	return b
 }
 
+// ToSql builds the query into a SQL string and bound args.
+func (b *SelectBuilder) ToSql() (string, []interface{}, error) {
+	sql, args, err := b.SelectBuilder.ToSql()
+	return comment(sql, b.table, b.tags), args, err
+}
+
 // Join adds a JOIN clause to the query.
 func (b *SelectBuilder) Join(cols ...t.Column) *SelectBuilder {
-	b.SelectBuilder = b.SelectBuilder.JoinClause("JOIN " + columnsToJoin(cols...))
+	b.SelectBuilder = b.SelectBuilder.JoinClause("JOIN " + b.columnsToJoin(cols...))
 	return b
 }
 
 // LeftJoin adds a LEFT JOIN clause to the query.
 func (b *SelectBuilder) LeftJoin(cols ...t.Column) *SelectBuilder {
-	b.SelectBuilder = b.SelectBuilder.JoinClause("LEFT JOIN " + columnsToJoin(cols...))
+	b.SelectBuilder = b.SelectBuilder.JoinClause("LEFT JOIN " + b.columnsToJoin(cols...))
 	return b
 }
 
 // RightJoin adds a RIGHT JOIN clause to the query.
 func (b *SelectBuilder) RightJoin(cols ...t.Column) *SelectBuilder {
-	b.SelectBuilder = b.SelectBuilder.JoinClause("RIGHT JOIN " + columnsToJoin(cols...))
+	b.SelectBuilder = b.SelectBuilder.JoinClause("RIGHT JOIN " + b.columnsToJoin(cols...))
 	return b
 }
 
@@ -46,21 +64,96 @@ func (b *SelectBuilder) RightJoin(cols ...t.Column) *SelectBuilder {
 func (b *SelectBuilder) Where(cols ...t.Column) *SelectBuilder {
 	where := sq.Eq{}
 	for _, c := range cols {
-		where[t.Q(c)] = c
+		where[b.q.Q(c)] = c
 	}
 	b.SelectBuilder = b.SelectBuilder.Where(where)
 	return b
 }
 
-func columnsToJoin(cols ...t.Column) string {
+// WherePred adds a predicate to the WHERE clause of the query.
+func (b *SelectBuilder) WherePred(p pred.Predicate) *SelectBuilder {
+	b.SelectBuilder = b.SelectBuilder.Where(rendered{p, b.q.Dialect()})
+	return b
+}
+
+// OrderBy adds ORDER BY expressions to the query.
+func (b *SelectBuilder) OrderBy(orderBys ...string) *SelectBuilder {
+	b.SelectBuilder = b.SelectBuilder.OrderBy(orderBys...)
+	return b
+}
+
+// Limit sets a LIMIT clause on the query.
+func (b *SelectBuilder) Limit(limit uint64) *SelectBuilder {
+	b.SelectBuilder = b.SelectBuilder.Limit(limit)
+	return b
+}
+
+// Offset sets an OFFSET clause on the query.
+func (b *SelectBuilder) Offset(offset uint64) *SelectBuilder {
+	b.SelectBuilder = b.SelectBuilder.Offset(offset)
+	return b
+}
+
+// Suffix adds an expression to the end of the query, such as FOR UPDATE.
+func (b *SelectBuilder) Suffix(sql string, args ...interface{}) *SelectBuilder {
+	b.SelectBuilder = b.SelectBuilder.Suffix(sql, args...)
+	return b
+}
+
+// RunWith sets the Runner the query is run with.
+func (b *SelectBuilder) RunWith(r Runner) *SelectBuilder {
+	b.runner = r
+	return b
+}
+
+// Exec builds and Execs the query with the Runner set by RunWith.
+func (b *SelectBuilder) Exec() (sql.Result, error) {
+	return b.ExecContext(context.Background())
+}
+
+// ExecContext builds and Execs the query with the Runner set by RunWith, bound to ctx.
+func (b *SelectBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	return exec(ctx, b.runner, b)
+}
+
+// Query builds the query and returns its rows from the Runner set by RunWith.
+func (b *SelectBuilder) Query() (*sql.Rows, error) {
+	return b.QueryContext(context.Background())
+}
+
+// QueryContext builds the query and returns its rows from the Runner set by RunWith, bound to ctx.
+func (b *SelectBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	return query(ctx, b.runner, b)
+}
+
+// QueryRow builds the query and returns its row from the Runner set by RunWith.
+func (b *SelectBuilder) QueryRow() RowScanner {
+	return b.QueryRowContext(context.Background())
+}
+
+// QueryRowContext builds the query and returns its row from the Runner set by RunWith, bound to ctx.
+func (b *SelectBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	return queryRow(ctx, b.runner, b)
+}
+
+// fker is a Column referencing a column in another table
+type fker interface {
+	FK() (t.Column, bool)
+}
+
+func (b *SelectBuilder) columnsToJoin(cols ...t.Column) string {
 	flatCols := []string{}
 	tableName := ""
 	for _, c := range cols {
-		if c2, ok := c.FK(); ok {
-			t1name := t.Q(c.Table())
-			c1name := t.Q(c)
-			t2name := t.Q(c2.Table())
-			c2name := t.Q(c2)
+		fk, ok := c.(fker)
+		if !ok {
+			continue
+		}
+		if c2, ok := fk.FK(); ok {
+			t1name := b.q.Q(c.Table())
+			c1name := b.q.Q(c)
+			t2name := b.q.Q(c2.Table())
+			c2name := b.q.Q(c2)
 			flatCols = append(flatCols, t1name+"."+c1name+"="+t2name+"."+c2name)
 			tableName = t1name
 		}
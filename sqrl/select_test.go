@@ -0,0 +1,96 @@
+package sqrl
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/pred"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
+)
+
+func TestSelect(tt *testing.T) {
+	s := New(dialect.Postgres)
+	cases := []struct {
+		name string
+		b    *SelectBuilder
+		sql  string
+		args []interface{}
+	}{
+		{"where", s.Select(userID(0), userEmail("")).From(users{}).Where(userID(1)),
+			`SELECT "id", "email" FROM "app"."users" WHERE "id" = $1`, []interface{}{userID(1)}},
+		{"predicate", s.Select(userID(0)).From(users{}).WherePred(pred.Gt(userID(1))).OrderBy(`"id" DESC`).Limit(10).Offset(20),
+			`SELECT "id" FROM "app"."users" WHERE "id" > $1 ORDER BY "id" DESC LIMIT 10 OFFSET 20`, []interface{}{userID(1)}},
+		{"embedded", grouped(s.Select(userEmail("")).From(users{})).Suffix("FOR UPDATE"),
+			`SELECT "email" FROM "app"."users" GROUP BY "email" HAVING count(*) > $1 FOR UPDATE`, []interface{}{1}},
+		{"comment", s.Comment(sqlcomment.Tags{"route": "/users"}).Select(userID(0)).From(users{}).Where(userID(1)),
+			`SELECT "id" FROM "app"."users" WHERE "id" = $1 /*route='%2Fusers',table='app.users'*/`, []interface{}{userID(1)}},
+	}
+	for _, c := range cases {
+		sql, args, err := c.b.ToSql()
+		if err != nil {
+			tt.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if sql != c.sql || !reflect.DeepEqual(args, c.args) {
+			tt.Errorf("%s: got %s %v, want %s %v", c.name, sql, args, c.sql, c.args)
+		}
+	}
+}
+
+func TestSelectRun(tt *testing.T) {
+	want := `SELECT "id" FROM "app"."users" WHERE "id" = $1 /*route='%2Fusers',table='app.users'*/`
+	b := New(dialect.Postgres).Comment(sqlcomment.Tags{"route": "/users"}).Select(userID(0)).From(users{}).Where(userID(1))
+	r := &runner{}
+	b.RunWith(r)
+	paths := []struct {
+		name string
+		run  func() error
+	}{
+		{"Exec", func() error { _, err := b.Exec(); return err }},
+		{"ExecContext", func() error { _, err := b.ExecContext(context.Background()); return err }},
+		{"Query", func() error { _, err := b.Query(); return ignoreRan(err) }},
+		{"QueryContext", func() error { _, err := b.QueryContext(context.Background()); return ignoreRan(err) }},
+		{"QueryRow", func() error { b.QueryRow(); return nil }},
+		{"QueryRowContext", func() error { b.QueryRowContext(context.Background()); return nil }},
+	}
+	for _, p := range paths {
+		r.queries = nil
+		if err := p.run(); err != nil {
+			tt.Errorf("%s: %v", p.name, err)
+		} else if !reflect.DeepEqual(r.queries, []string{want}) || !reflect.DeepEqual(r.args, [][]interface{}{{userID(1)}}) {
+			tt.Errorf("%s: ran %q %v, want %q", p.name, r.queries, r.args, want)
+		}
+		r.args = nil
+	}
+
+	unrun := New(dialect.Postgres).Select(userID(0)).From(users{})
+	if _, err := unrun.Exec(); err != ErrNoRunner {
+		tt.Errorf("exec: got %v without a runner, want ErrNoRunner", err)
+	}
+	if _, err := unrun.Query(); err != ErrNoRunner {
+		tt.Errorf("query: got %v without a runner, want ErrNoRunner", err)
+	}
+	if err := unrun.QueryRow().Scan(); err != ErrNoRunner {
+		tt.Errorf("query row: got %v without a runner, want ErrNoRunner", err)
+	}
+	if err := New(dialect.Postgres).Select().RunWith(r).QueryRow().Scan(); err == nil {
+		tt.Error("query row: scanned a select without columns")
+	}
+}
+
+// grouped groups b through the embedded sqrl builder
+func grouped(b *SelectBuilder) *SelectBuilder {
+	b.GroupBy(`"email"`)
+	b.Having("count(*) > ?", 1)
+	return b
+}
+
+// ignoreRan drops the error of the queries of runner
+func ignoreRan(err error) error {
+	if err == errRan {
+		return nil
+	}
+	return err
+}
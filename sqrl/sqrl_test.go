@@ -0,0 +1,62 @@
+package sqrl
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	t "github.com/pindamonhangaba/tabua"
+)
+
+// users is a table of the app schema
+type users struct{}
+
+func (users) Name() string                { return "users" }
+func (users) Schema() string              { return "app" }
+func (users) Constraints() []t.Constraint { return nil }
+func (users) Columns() []t.Column         { return []t.Column{userID(0), userEmail("")} }
+
+type userID int64
+
+func (userID) Name() string                   { return "id" }
+func (userID) Table() t.Table                 { return users{} }
+func (userID) SQLType() string                { return "int8" }
+func (userID) NonNull() bool                  { return true }
+func (c userID) Value() (driver.Value, error) { return int64(c), nil }
+
+type userEmail string
+
+func (userEmail) Name() string                   { return "email" }
+func (userEmail) Table() t.Table                 { return users{} }
+func (userEmail) SQLType() string                { return "text" }
+func (userEmail) NonNull() bool                  { return true }
+func (c userEmail) Value() (driver.Value, error) { return string(c), nil }
+
+var errRan = errors.New("ran")
+
+// runner records the statements run with it
+type runner struct {
+	queries []string
+	args    [][]interface{}
+}
+
+func (r *runner) record(query string, args []interface{}) {
+	r.queries = append(r.queries, query)
+	r.args = append(r.args, args)
+}
+
+func (r *runner) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	r.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (r *runner) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	r.record(query, args)
+	return nil, errRan
+}
+
+func (r *runner) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	r.record(query, args)
+	return nil
+}
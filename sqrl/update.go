@@ -1,25 +1,41 @@
 package sqrl
 
 import (
+	"context"
+	"database/sql"
+
 	sq "github.com/elgris/sqrl"
 	t "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/op"
+	"github.com/pindamonhangaba/tabua/pred"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
 )
 
 // UpdateBuilder builds SQL UPDATE statements.
+// Statements without a WHERE are refused with tabua.UnconditionalError unless AllRows is called,
+// by ToSql and every method running them. Only Where and WherePred count as a condition,
+// one added on the embedded sqrl builder needs AllRows.
 type UpdateBuilder struct {
 	*sq.UpdateBuilder
+	q      op.Quoter
+	table  t.Table
+	tags   sqlcomment.Tags
+	runner Runner
+	where  bool
+	all    bool
 }
 
 // Table sets the table to be updateb.
 func (b *UpdateBuilder) Table(table t.Table) *UpdateBuilder {
-	b.UpdateBuilder = b.UpdateBuilder.Table(t.Q(table))
+	b.UpdateBuilder = b.UpdateBuilder.Table(b.q.Q(table))
+	b.table = table
 	return b
 }
 
 // Set adds SET clauses to the query.
 func (b *UpdateBuilder) Set(cols ...t.Column) *UpdateBuilder {
 	for _, c := range cols {
-		b.UpdateBuilder = b.UpdateBuilder.Set(t.Q(c), c)
+		b.UpdateBuilder = b.UpdateBuilder.Set(b.q.Q(c), c)
 	}
 	return b
 }
@@ -30,8 +46,86 @@ func (b *UpdateBuilder) Set(cols ...t.Column) *UpdateBuilder {
 func (b *UpdateBuilder) Where(cols ...t.Column) *UpdateBuilder {
 	where := sq.Eq{}
 	for _, c := range cols {
-		where[t.Q(c)] = c
+		where[b.q.Q(c)] = c
 	}
 	b.UpdateBuilder = b.UpdateBuilder.Where(where)
+	b.where = b.where || len(cols) > 0
+	return b
+}
+
+// WherePred adds a predicate to the WHERE clause of the query.
+// A predicate grouping no conditions, see pred.Empty, still needs AllRows.
+func (b *UpdateBuilder) WherePred(p pred.Predicate) *UpdateBuilder {
+	b.UpdateBuilder = b.UpdateBuilder.Where(rendered{p, b.q.Dialect()})
+	// an empty group renders as a constant, it's no condition
+	b.where = b.where || !pred.Empty(p)
+	return b
+}
+
+// Suffix adds an expression to the end of the query, such as RETURNING.
+func (b *UpdateBuilder) Suffix(sql string, args ...interface{}) *UpdateBuilder {
+	b.UpdateBuilder = b.UpdateBuilder.Suffix(sql, args...)
+	return b
+}
+
+// AllRows allows the query to update every row, without a WHERE clause.
+func (b *UpdateBuilder) AllRows() *UpdateBuilder {
+	b.all = true
+	return b
+}
+
+// RunWith sets the Runner the query is run with.
+func (b *UpdateBuilder) RunWith(r Runner) *UpdateBuilder {
+	b.runner = r
 	return b
 }
+
+// ToSql builds the query into a SQL string and bound args.
+func (b *UpdateBuilder) ToSql() (string, []interface{}, error) {
+	if err := b.guard(); err != nil {
+		return "", nil, err
+	}
+	sql, args, err := b.UpdateBuilder.ToSql()
+	return comment(sql, b.table, b.tags), args, err
+}
+
+// Exec builds and Execs the query with the Runner set by RunWith.
+func (b *UpdateBuilder) Exec() (sql.Result, error) {
+	return b.ExecContext(context.Background())
+}
+
+// ExecContext builds and Execs the query with the Runner set by RunWith, bound to ctx.
+func (b *UpdateBuilder) ExecContext(ctx context.Context) (sql.Result, error) {
+	return exec(ctx, b.runner, b)
+}
+
+// Query builds the query and returns its rows from the Runner set by RunWith.
+func (b *UpdateBuilder) Query() (*sql.Rows, error) {
+	return b.QueryContext(context.Background())
+}
+
+// QueryContext builds the query and returns its rows from the Runner set by RunWith, bound to ctx.
+func (b *UpdateBuilder) QueryContext(ctx context.Context) (*sql.Rows, error) {
+	return query(ctx, b.runner, b)
+}
+
+// QueryRow builds the query and returns its row from the Runner set by RunWith.
+func (b *UpdateBuilder) QueryRow() RowScanner {
+	return b.QueryRowContext(context.Background())
+}
+
+// QueryRowContext builds the query and returns its row from the Runner set by RunWith, bound to ctx.
+func (b *UpdateBuilder) QueryRowContext(ctx context.Context) RowScanner {
+	return queryRow(ctx, b.runner, b)
+}
+
+func (b *UpdateBuilder) guard() error {
+	if !b.where && !b.all {
+		name := ""
+		if b.table != nil {
+			name = b.table.Name()
+		}
+		return t.UnconditionalError{Statement: "UPDATE", Table: name}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package sqrl
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	t "github.com/pindamonhangaba/tabua"
+	"github.com/pindamonhangaba/tabua/dialect"
+	"github.com/pindamonhangaba/tabua/pred"
+	"github.com/pindamonhangaba/tabua/sqlcomment"
+)
+
+func TestUpdate(tt *testing.T) {
+	s := New(dialect.Postgres)
+	cases := []struct {
+		name string
+		b    *UpdateBuilder
+		sql  string
+		args []interface{}
+	}{
+		{"where", s.Update(users{}).Set(userEmail("a")).Where(userID(1)),
+			`UPDATE "app"."users" SET "email" = $1 WHERE "id" = $2`, []interface{}{userEmail("a"), userID(1)}},
+		{"predicate", s.Update(users{}).Set(userEmail("a")).WherePred(pred.Gt(userID(1))).Suffix(`RETURNING "id"`),
+			`UPDATE "app"."users" SET "email" = $1 WHERE "id" > $2 RETURNING "id"`, []interface{}{userEmail("a"), userID(1)}},
+		{"all rows", s.Update(users{}).Set(userEmail("a")).AllRows(),
+			`UPDATE "app"."users" SET "email" = $1`, []interface{}{userEmail("a")}},
+		{"embedded", prefixed(s.Update(users{}).Set(userEmail("a")).Where(userID(1))),
+			`WITH x AS (SELECT 1) UPDATE "app"."users" SET "email" = $1 WHERE "id" = $2`, []interface{}{userEmail("a"), userID(1)}},
+		{"comment", s.Comment(sqlcomment.Tags{"app": "api"}).Update(users{}).Set(userEmail("a")).Where(userID(1)),
+			`UPDATE "app"."users" SET "email" = $1 WHERE "id" = $2 /*app='api',table='app.users'*/`, []interface{}{userEmail("a"), userID(1)}},
+	}
+	for _, c := range cases {
+		sql, args, err := c.b.ToSql()
+		if err != nil {
+			tt.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if sql != c.sql || !reflect.DeepEqual(args, c.args) {
+			tt.Errorf("%s: got %s %v, want %s %v", c.name, sql, args, c.sql, c.args)
+		}
+
+		r := &runner{}
+		if _, err := c.b.RunWith(r).Exec(); err != nil {
+			tt.Errorf("%s: %v", c.name, err)
+		}
+		c.b.Query()
+		c.b.QueryRow()
+		if !reflect.DeepEqual(r.queries, []string{c.sql, c.sql, c.sql}) {
+			tt.Errorf("%s: ran %q, want %q", c.name, r.queries, c.sql)
+		}
+	}
+}
+
+func TestUpdateUnconditional(tt *testing.T) {
+	unconditional := func() *UpdateBuilder {
+		return New(dialect.Postgres).Update(users{}).Set(userEmail("a")).Where()
+	}
+	r := &runner{}
+	paths := []struct {
+		name string
+		run  func(*UpdateBuilder) error
+	}{
+		{"ToSql", func(b *UpdateBuilder) error { _, _, err := b.ToSql(); return err }},
+		{"Exec", func(b *UpdateBuilder) error { _, err := b.RunWith(r).Exec(); return err }},
+		{"ExecContext", func(b *UpdateBuilder) error { _, err := b.RunWith(r).ExecContext(context.Background()); return err }},
+		{"QueryContext", func(b *UpdateBuilder) error { _, err := b.RunWith(r).QueryContext(context.Background()); return err }},
+		{"QueryRowContext", func(b *UpdateBuilder) error { return b.RunWith(r).QueryRowContext(context.Background()).Scan() }},
+		{"Suffix", func(b *UpdateBuilder) error { _, err := b.Suffix("RETURNING 1").RunWith(r).Exec(); return err }},
+	}
+	for _, p := range paths {
+		err := p.run(unconditional())
+		if u, ok := err.(t.UnconditionalError); !ok || u.Statement != "UPDATE" || u.Table != "users" {
+			tt.Errorf("%s: got %v, want an UnconditionalError", p.name, err)
+		}
+	}
+	if len(r.queries) > 0 {
+		tt.Errorf("ran %q", r.queries)
+	}
+	empty := []pred.Predicate{pred.And(), pred.Or(pred.And()), pred.Not(pred.Or())}
+	for _, p := range empty {
+		_, _, err := New(dialect.Postgres).Update(users{}).Set(userEmail("a")).WherePred(p).ToSql()
+		if _, ok := err.(t.UnconditionalError); !ok {
+			tt.Errorf("%#v: got %v, want an UnconditionalError", p, err)
+		}
+	}
+	if _, err := New(dialect.Postgres).Update(users{}).Set(userEmail("a")).Where(userID(1)).Exec(); err != ErrNoRunner {
+		tt.Errorf("got %v without a runner, want ErrNoRunner", err)
+	}
+}
+
+// prefixed adds a prefix through the embedded sqrl builder
+func prefixed(b *UpdateBuilder) *UpdateBuilder {
+	b.Prefix("WITH x AS (SELECT 1)")
+	return b
+}
@@ -1,11 +1,5 @@
 package tabua
 
-// ValueQuotes sets the quote char to use when quoting values
-var ValueQuotes = `'`
-
-// NameQuotes sets the quote char to use when quoting table and columnnames
-var NameQuotes = `"`
-
 type ConstraintType string
 
 // PSQL Constraint types
@@ -19,12 +13,57 @@ const (
 // Constraint returns the table constraint name
 type Constraint string
 
-// Constrainer returns the constraint's type
+// Constrainer returns the constraint's name and type
 type Constrainer interface {
+	Namer
 	Type() ConstraintType
 	Definition() string
 }
 
+// Constrained is a Table listing its typed constraints
+type Constrained interface {
+	Constrainers() []Constrainer
+}
+
+// SoftDeleter is a Table whose rows are marked deleted by setting a nullable column instead of removed
+type SoftDeleter interface {
+	DeletedColumn() Column
+}
+
+// Versioned is a Table locked optimistically by a version column, an integer or a timestamp
+// every update bumps, and only applies to rows still at the version it sets
+type Versioned interface {
+	VersionColumn() Column
+}
+
+// Indexer is a Table listing its indexes
+type Indexer interface {
+	Indexes() []Index
+}
+
+// Index describes an index of a Table
+type Index struct {
+	Name string
+	// Method is the access method of the index, btree, hash, gist, gin, brin...
+	Method  string
+	Unique  bool
+	Primary bool
+	// Keys are the indexed columns and expressions, in order
+	Keys []IndexKey
+	// Include holds the non key columns of a covering index
+	Include []Column
+	// Where is the predicate of a partial index, empty for a full one
+	Where string
+	// Definition is the CREATE INDEX statement of the index
+	Definition string
+}
+
+// IndexKey is a key of an Index, a Column or else an SQL Expression
+type IndexKey struct {
+	Column     Column
+	Expression string
+}
+
 // UniqueConstrainer limits Column to unique values
 type UniqueConstrainer interface {
 	Constrainer
@@ -54,6 +93,11 @@ type Namer interface {
 	Name() string
 }
 
+// Schemer describes the schema a Table belongs to
+type Schemer interface {
+	Schema() string
+}
+
 //FK represents basic information for a database foreignkey entry
 type FK struct {
 	From []Column
@@ -68,6 +112,22 @@ type Column interface {
 	NonNull() bool
 }
 
+// Limited is a Column of strings of at most Length characters, a varchar(n) or char(n)
+type Limited interface {
+	Length() int
+}
+
+// Precise is a Column of numbers of at most Precision digits, Scale of them after the decimal point
+type Precise interface {
+	Precision() int
+	Scale() int
+}
+
+// Enumerated is a Column restricted to the Labels of an enum type
+type Enumerated interface {
+	Labels() []string
+}
+
 // Table describes an SQL table
 type Table interface {
 	Namer
@@ -80,3 +140,48 @@ type Querier interface {
 	SQL() string
 	Args() []interface{}
 }
+
+// Dialect describes the SQL syntax of a database
+type Dialect interface {
+	Namer
+	// QuoteIdent quotes a table or column name
+	QuoteIdent(name string) string
+	// QuoteValue quotes a string literal
+	QuoteValue(v string) string
+	// Placeholder returns the bind parameter for the nth argument, starting at 1
+	Placeholder(n int) string
+	// Returning reports if INSERT, UPDATE and DELETE support a RETURNING clause
+	Returning() bool
+	// Upsert returns the clause that resolves an INSERT conflict, with ? placeholders
+	Upsert(c Conflict) (string, []interface{}, error)
+	// Bool returns a boolean literal
+	Bool(b bool) string
+	// Limit returns a LIMIT/OFFSET clause, zero values are left out
+	Limit(limit, offset uint64) string
+	// OrderBy returns the ORDER BY term for a quoted column
+	OrderBy(col string, desc bool, nulls Nulls) string
+}
+
+// Nulls places NULL values in an ORDER BY
+type Nulls int
+
+// Nulls placements, NullsDefault leaves them where the database sorts them
+const (
+	NullsDefault Nulls = iota
+	NullsFirst
+	NullsLast
+)
+
+// Conflict describes how an INSERT resolves rows that already exist
+type Conflict struct {
+	// Target holds the quoted columns of the conflicting key
+	Target []string
+	// Constraint is the quoted name of the conflicting constraint, preferred over Target
+	Constraint string
+	// TargetWhere is the predicate of the partial unique index on Target
+	TargetWhere Querier
+	// Update holds the quoted columns overwritten by the new row, none means do nothing
+	Update []string
+	// UpdateWhere limits the updated rows to those it matches
+	UpdateWhere Querier
+}
@@ -0,0 +1,163 @@
+package tabua
+
+import (
+	"database/sql/driver"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationErrors lists every ColumnValidationError of the validated columns
+type ValidationErrors []ColumnValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, cve := range e {
+		msgs = append(msgs, cve.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns each ColumnValidationError, for errors.As
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, cve := range e {
+		errs = append(errs, cve)
+	}
+	return errs
+}
+
+// Validate checks the columns of row before it's written, see ValidateColumns
+func Validate(row Table) error {
+	return ValidateColumns(row.Columns()...)
+}
+
+// ValidateColumns checks the values of cols, of the same table, against what the database would refuse:
+// NULL in a NonNull column, strings too long for a Limited column, numbers out of range of a Precise column,
+// values missing from the Labels of an Enumerated column and, when the table is Constrained,
+// CHECK constraints on cols alone that are simple enough to evaluate in Go, the others are left to the database
+// It returns nil or the ValidationErrors of every failure
+func ValidateColumns(cols ...Column) error {
+	errs := ValidationErrors{}
+	values := row{}
+	for _, c := range cols {
+		v, err := ValueOf(c)
+		if err != nil {
+			errs = append(errs, ColumnValidationError{c.Name(), err.Error()})
+			continue
+		}
+		values[c.Name()] = v
+		if msg, ok := validateValue(c, v); !ok {
+			errs = append(errs, ColumnValidationError{c.Name(), msg})
+		}
+	}
+	if len(cols) > 0 {
+		if t, ok := cols[0].Table().(Constrained); ok {
+			for _, cst := range t.Constrainers() {
+				if cst.Type() != ConstraintCheck {
+					continue
+				}
+				if pass, ok := evalCheck(cst.Definition(), values); ok && !pass {
+					errs = append(errs, ColumnValidationError{checkColumns(cst), "violates check constraint " + cst.Name()})
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateValue checks v, the value of c, and returns why it's invalid
+func validateValue(c Column, v driver.Value) (string, bool) {
+	if v == nil {
+		if c.NonNull() {
+			return "null value in a not null column", false
+		}
+		return "", true
+	}
+	s, isText := text(v)
+	if l, ok := c.(Limited); ok && isText && l.Length() > 0 {
+		// the database drops trailing spaces past the limit
+		if n := utf8.RuneCountInString(strings.TrimRight(s, " ")); n > l.Length() {
+			return "value of " + strconv.Itoa(n) + " characters is longer than " + strconv.Itoa(l.Length()), false
+		}
+	}
+	if e, ok := c.(Enumerated); ok && isText {
+		found := false
+		for _, label := range e.Labels() {
+			if s == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "value " + strconv.Quote(s) + " is not one of " + strings.Join(e.Labels(), ", "), false
+		}
+	}
+	if p, ok := c.(Precise); ok && p.Precision() > 0 {
+		n, isNumber := number(v)
+		if !isNumber {
+			return "value is not a number", false
+		}
+		if !fits(n, p.Precision(), p.Scale()) {
+			return "value out of range of numeric(" + strconv.Itoa(p.Precision()) + "," + strconv.Itoa(p.Scale()) + ")", false
+		}
+	}
+	return "", true
+}
+
+// fits reports if n, rounded to scale digits after the decimal point, has at most precision digits
+func fits(n *big.Rat, precision, scale int) bool {
+	// round half away from zero, as numeric does
+	scaled := new(big.Rat).Mul(n, new(big.Rat).SetInt(pow10(scale)))
+	q, r := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(scaled.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(scaled.Sign())))
+	}
+	return q.CmpAbs(pow10(precision)) < 0
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// text returns the string of a text driver value
+func text(v driver.Value) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+// number returns the value of a numeric driver value as a numeric column stores it, numeric columns are often scanned to strings
+func number(v driver.Value) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case float64:
+		// as postgres casts double precision to numeric, to 15 significant digits
+		return new(big.Rat).SetString(strconv.FormatFloat(v, 'g', 15, 64))
+	case string, []byte:
+		s, _ := text(v)
+		return new(big.Rat).SetString(strings.TrimSpace(s))
+	}
+	return nil, false
+}
+
+func checkColumns(c Constrainer) string {
+	cc, ok := c.(CheckConstrainer)
+	if !ok || len(cc.Columns()) < 1 {
+		return c.Name()
+	}
+	names := []string{}
+	for _, col := range cc.Columns() {
+		names = append(names, col.Name())
+	}
+	return strings.Join(names, ", ")
+}
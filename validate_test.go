@@ -0,0 +1,211 @@
+package tabua
+
+import (
+	"database/sql"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+// items has columns of every validated kind and a check constraint
+type items struct {
+	Title  itemName
+	Price  itemPrice
+	Ratio  itemRatio
+	Status itemStatus
+	Code   itemCode
+}
+
+func (items) Name() string              { return "items" }
+func (items) Constraints() []Constraint { return nil }
+func (i items) Columns() []Column       { return []Column{i.Title, i.Price, i.Ratio, i.Status, i.Code} }
+func (items) Constrainers() []Constrainer {
+	return []Constrainer{itemsRatioCheck{}, itemsUncheckable{}, itemsRoundedCheck{}}
+}
+
+type itemName string
+
+func (itemName) Name() string    { return "name" }
+func (itemName) Table() Table    { return items{} }
+func (itemName) SQLType() string { return "varchar,character varying" }
+func (itemName) NonNull() bool   { return true }
+func (itemName) Length() int     { return 5 }
+
+type itemPrice float64
+
+func (itemPrice) Name() string    { return "price" }
+func (itemPrice) Table() Table    { return items{} }
+func (itemPrice) SQLType() string { return "numeric,numeric" }
+func (itemPrice) NonNull() bool   { return true }
+func (itemPrice) Precision() int  { return 4 }
+func (itemPrice) Scale() int      { return 2 }
+
+type itemRatio float64
+
+func (itemRatio) Name() string    { return "ratio" }
+func (itemRatio) Table() Table    { return items{} }
+func (itemRatio) SQLType() string { return "float8,double precision" }
+func (itemRatio) NonNull() bool   { return true }
+
+type itemStatus string
+
+func (itemStatus) Name() string     { return "status" }
+func (itemStatus) Table() Table     { return items{} }
+func (itemStatus) SQLType() string  { return "item_status,USER-DEFINED" }
+func (itemStatus) NonNull() bool    { return true }
+func (itemStatus) Labels() []string { return []string{"active", "closed"} }
+
+type itemCode struct{ sql.NullString }
+
+func (itemCode) Name() string    { return "code" }
+func (itemCode) Table() Table    { return items{} }
+func (itemCode) SQLType() string { return "text,text" }
+func (itemCode) NonNull() bool   { return true }
+
+type itemsRatioCheck struct{}
+
+func (itemsRatioCheck) Name() string         { return "items_ratio_check" }
+func (itemsRatioCheck) Type() ConstraintType { return ConstraintCheck }
+func (itemsRatioCheck) Definition() string   { return "CHECK ((ratio <= (0.1)::double precision))" }
+func (itemsRatioCheck) Columns() []Column    { return []Column{itemRatio(0)} }
+
+// itemsUncheckable is left to the database
+type itemsUncheckable struct{}
+
+func (itemsUncheckable) Name() string         { return "items_name_check" }
+func (itemsUncheckable) Type() ConstraintType { return ConstraintCheck }
+func (itemsUncheckable) Definition() string   { return "CHECK ((name ~~ 'a%'::text))" }
+
+// itemsRoundedCheck rounds the ratio, it's left to the database too
+type itemsRoundedCheck struct{}
+
+func (itemsRoundedCheck) Name() string         { return "items_ratio_rounded_check" }
+func (itemsRoundedCheck) Type() ConstraintType { return ConstraintCheck }
+func (itemsRoundedCheck) Definition() string   { return "CHECK (((ratio)::integer >= 0))" }
+func (itemsRoundedCheck) Columns() []Column    { return []Column{itemRatio(0)} }
+
+func validItem() items {
+	return items{Title: "abc", Price: 99.99, Ratio: 0.1, Status: "active", Code: itemCode{sql.NullString{String: "x", Valid: true}}}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		edit func(i *items)
+		want ValidationErrors
+	}{
+		{"valid", func(*items) {}, nil},
+		{"trailing spaces", func(i *items) { i.Title = "abcde   " }, nil},
+		{"too long", func(i *items) { i.Title = "abcdef" },
+			ValidationErrors{{"name", "value of 6 characters is longer than 5"}}},
+		{"characters", func(i *items) { i.Title = "ação!" }, nil},
+		{"rounded into range", func(i *items) { i.Price = 99.994 }, nil},
+		{"rounded out of range", func(i *items) { i.Price = 99.995 },
+			ValidationErrors{{"price", "value out of range of numeric(4,2)"}}},
+		{"negative", func(i *items) { i.Price = -99.99 }, nil},
+		{"not a number", func(i *items) { i.Price = itemPrice(math.Inf(1)) },
+			ValidationErrors{{"price", "value is not a number"}}},
+		{"check at the limit", func(i *items) { i.Ratio = 0.1 }, nil},
+		{"rounding cast", func(i *items) { i.Ratio = -0.4 }, nil},
+		{"check", func(i *items) { i.Ratio = 0.2 },
+			ValidationErrors{{"ratio", "violates check constraint items_ratio_check"}}},
+		{"label", func(i *items) { i.Status = "gone" },
+			ValidationErrors{{"status", `value "gone" is not one of active, closed`}}},
+		{"null", func(i *items) { i.Code = itemCode{} },
+			ValidationErrors{{"code", "null value in a not null column"}}},
+		{"every failure", func(i *items) { i.Title, i.Status, i.Ratio = "abcdef", "gone", 1 },
+			ValidationErrors{
+				{"name", "value of 6 characters is longer than 5"},
+				{"status", `value "gone" is not one of active, closed`},
+				{"ratio", "violates check constraint items_ratio_check"},
+			}},
+	}
+	for _, c := range cases {
+		i := validItem()
+		c.edit(&i)
+		err := Validate(i)
+		if c.want == nil {
+			if err != nil {
+				t.Errorf("%s: got %v", c.name, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(err, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.want)
+		}
+	}
+
+	err := ValidateColumns(itemName("abcdef"), itemRatio(0.5))
+	var cve ColumnValidationError
+	if !errors.As(err, &cve) || cve.ColumnName != "name" {
+		t.Errorf("got %v, want a ColumnValidationError of name", err)
+	}
+	if err.Error() != "name: value of 6 characters is longer than 5; ratio: violates check constraint items_ratio_check" {
+		t.Errorf("got %q", err.Error())
+	}
+	if err := ValidateColumns(); err != nil {
+		t.Errorf("got %v for no columns", err)
+	}
+	// a check on columns left out isn't evaluated
+	if err := ValidateColumns(itemName("abc")); err != nil {
+		t.Errorf("got %v for a check on other columns", err)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	cases := []struct {
+		v    interface{}
+		want string
+		ok   bool
+	}{
+		{int64(-3), "-3", true},
+		{0.1, "1/10", true},
+		{99.995, "19999/200", true},
+		{1e20, "100000000000000000000", true},
+		{"1.50", "3/2", true},
+		{[]byte(" 2 "), "2", true},
+		{math.NaN(), "", false},
+		{math.Inf(-1), "", false},
+		{"x", "", false},
+		{true, "", false},
+	}
+	for _, c := range cases {
+		n, ok := number(c.v)
+		if ok != c.ok || ok && n.Cmp(mustRat(c.want)) != 0 {
+			t.Errorf("%#v: got %v %v, want %s %v", c.v, n, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestFits(t *testing.T) {
+	cases := []struct {
+		n                string
+		precision, scale int
+		want             bool
+	}{
+		{"99.99", 4, 2, true},
+		{"99.995", 4, 2, false},
+		{"-99.995", 4, 2, false},
+		{"99.9949", 4, 2, true},
+		{"0.001", 4, 2, true},
+		{"100", 4, 2, false},
+		{"12345", 5, 0, true},
+		{"12345.5", 5, 0, true},
+		{"99999.5", 5, 0, false},
+	}
+	for _, c := range cases {
+		if got := fits(mustRat(c.n), c.precision, c.scale); got != c.want {
+			t.Errorf("%s in numeric(%d,%d): got %v, want %v", c.n, c.precision, c.scale, got, c.want)
+		}
+	}
+}
+
+func mustRat(s string) *big.Rat {
+	n, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("not a number " + s)
+	}
+	return n
+}
@@ -0,0 +1,47 @@
+package tabua
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// ValueOf returns the driver value of v, used by generated columns
+// wrapping types from other packages, which don't inherit their methods
+func ValueOf(v interface{}) (driver.Value, error) {
+	if vr, ok := v.(driver.Valuer); ok {
+		return vr.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// ScanInto scans src into dst, a pointer, the counterpart of ValueOf
+func ScanInto(dst interface{}, src interface{}) error {
+	if s, ok := dst.(sql.Scanner); ok {
+		return s.Scan(src)
+	}
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("tabua: scan destination %T is not a pointer", dst)
+	}
+	dv = dv.Elem()
+	if src == nil {
+		dv.Set(reflect.Zero(dv.Type()))
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if b, ok := src.([]byte); ok && dv.Kind() == reflect.String {
+		dv.SetString(string(b))
+		return nil
+	}
+	if sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+	if sv.Kind() == dv.Kind() && sv.Type().ConvertibleTo(dv.Type()) {
+		dv.Set(sv.Convert(dv.Type()))
+		return nil
+	}
+	return fmt.Errorf("tabua: can't scan %T into %T", src, dst)
+}
@@ -0,0 +1,58 @@
+package tabua
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+type celsius float64
+
+func TestValueOf(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		v    interface{}
+		want interface{}
+	}{
+		{int32(3), int64(3)},
+		{celsius(1.5), 1.5},
+		{"s", "s"},
+		{now, now},
+		{sql.NullString{String: "n", Valid: true}, "n"},
+		{sql.NullInt64{}, nil},
+	}
+	for _, c := range cases {
+		got, err := ValueOf(c.v)
+		if err != nil || got != c.want {
+			t.Errorf("%#v: got %#v %v, want %#v", c.v, got, err, c.want)
+		}
+	}
+	if _, err := ValueOf(struct{}{}); err == nil {
+		t.Error("converted a struct")
+	}
+}
+
+func TestScanInto(t *testing.T) {
+	var s string
+	if err := ScanInto(&s, []byte("bytes")); err != nil || s != "bytes" {
+		t.Errorf("bytes into string: got %q %v", s, err)
+	}
+	var c celsius
+	if err := ScanInto(&c, 2.5); err != nil || c != 2.5 {
+		t.Errorf("float64 into celsius: got %v %v", c, err)
+	}
+	var ns sql.NullString
+	if err := ScanInto(&ns, "x"); err != nil || ns.String != "x" || !ns.Valid {
+		t.Errorf("into a Scanner: got %+v %v", ns, err)
+	}
+	n := 7
+	if err := ScanInto(&n, nil); err != nil || n != 0 {
+		t.Errorf("nil: got %v %v", n, err)
+	}
+	if err := ScanInto(n, 1); err == nil {
+		t.Error("scanned into a non pointer")
+	}
+	if err := ScanInto(&n, "x"); err == nil {
+		t.Error("scanned a string into an int")
+	}
+}